@@ -1,14 +1,12 @@
 module github.com/cvbarros/terraform-provider-teamcity
 
-go 1.13
+go 1.16
 
 require (
 	github.com/cvbarros/go-teamcity v1.2.1-0.20201118115426-9d399240bca8
 	github.com/dghubble/sling v1.3.0 // indirect
-	github.com/hashicorp/hcl v1.0.0 // indirect
-	github.com/hashicorp/terraform-plugin-sdk v1.9.0
-	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
+	github.com/hashicorp/terraform-plugin-sdk/v2 v2.10.1
 	github.com/motemen/go-nuts v0.0.0-20190725124253-1d2432db96b0 // indirect
-	github.com/ulikunitz/xz v0.5.6 // indirect
-	github.com/vmihailenco/msgpack v4.0.4+incompatible // indirect
+	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
 )
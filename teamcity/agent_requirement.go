@@ -0,0 +1,32 @@
+package teamcity
+
+import (
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The api client sends the requests creating and reading agent requirements through `http.DefaultClient`,
+// so they are sent through the rest client instead.
+
+func agentRequirementsPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/agent-requirements", api.LocatorID(buildConfigID))
+}
+
+func (r *restClient) addAgentRequirement(buildConfigID string, req *api.AgentRequirement) (*api.AgentRequirement, error) {
+	var out api.AgentRequirement
+	if err := r.post(agentRequirementsPath(buildConfigID), req, &out, "agent requirement"); err != nil {
+		return nil, err
+	}
+	out.BuildTypeID = buildConfigID
+	return &out, nil
+}
+
+func (r *restClient) getAgentRequirement(buildConfigID string, id string) (*api.AgentRequirement, error) {
+	var out api.AgentRequirement
+	if err := r.get(fmt.Sprintf("%s/%s", agentRequirementsPath(buildConfigID), id), &out, "agent requirement"); err != nil {
+		return nil, err
+	}
+	out.BuildTypeID = buildConfigID
+	return &out, nil
+}
@@ -0,0 +1,148 @@
+package teamcity_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestBuildConfigLock_SerialisesChangesToBuildConfig(t *testing.T) {
+	srv := newConcurrentWritesServer(t)
+	defer srv.Close()
+
+	p := teamcity.Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"address": srv.URL,
+		"token":   "token",
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	r := p.ResourcesMap["teamcity_build_trigger_vcs"]
+	var wg sync.WaitGroup
+	errs := make(chan diag.Diagnostics, 6)
+	for _, bt := range []string{"BuildA", "BuildB"} {
+		for i := 0; i < 3; i++ {
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"build_config_id": bt,
+				"rules":           []interface{}{fmt.Sprintf("+:path%d/**", i)},
+			})
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- r.CreateContext(context.Background(), d, p.Meta())
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for diags := range errs {
+		if diags.HasError() {
+			t.Errorf("unexpected error creating trigger: %v", diags)
+		}
+	}
+	if srv.maxInFlight < 2 {
+		t.Errorf("expected changes to different build configurations to run in parallel, max in flight: %d", srv.maxInFlight)
+	}
+}
+
+// concurrentWritesServer fakes the TeamCity trigger endpoints, rejecting a write to a build configuration
+// while another write to the same build configuration is in flight
+type concurrentWritesServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxInFlight int
+	triggers    map[string]string
+	lastID      int
+}
+
+func newConcurrentWritesServer(t *testing.T) *concurrentWritesServer {
+	s := &concurrentWritesServer{
+		inFlight: make(map[string]int),
+		triggers: make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/app/rest/"), "/"), "/")
+		switch {
+		case len(path) == 1 && path[0] == "server":
+			w.Write([]byte(`{"version": "2019.2.2 (build 72059)", "versionMajor": 2019, "versionMinor": 2}`))
+		case len(path) == 2 && path[0] == "buildTypes" && r.Method == http.MethodGet:
+			w.Write([]byte(fmt.Sprintf(`{"id": "%s", "settings": {"property": []}, "steps": {"count": 0}, "vcs-root-entries": {"count": 0}, "parameters": {"property": []}}`, strings.TrimPrefix(path[1], "id:"))))
+		case len(path) == 3 && path[2] == "triggers" && r.Method == http.MethodPost:
+			s.addTrigger(t, w, r, strings.TrimPrefix(path[1], "id:"))
+		case len(path) == 4 && path[2] == "triggers" && r.Method == http.MethodGet:
+			s.mu.Lock()
+			trigger, ok := s.triggers[path[3]]
+			s.mu.Unlock()
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(trigger))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+func (s *concurrentWritesServer) addTrigger(t *testing.T, w http.ResponseWriter, r *http.Request, buildTypeID string) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.inFlight[buildTypeID]++
+	conflict := s.inFlight[buildTypeID] > 1
+	total := 0
+	for _, n := range s.inFlight {
+		total += n
+	}
+	if total > s.maxInFlight {
+		s.maxInFlight = total
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inFlight[buildTypeID]--
+		s.mu.Unlock()
+	}()
+
+	if conflict {
+		t.Errorf("concurrent write to build configuration '%s'", buildTypeID)
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	// Keep the write in flight long enough for concurrent ones to overlap
+	time.Sleep(50 * time.Millisecond)
+
+	s.mu.Lock()
+	s.lastID++
+	id := fmt.Sprintf("TRIGGER_%d", s.lastID)
+	var trigger map[string]interface{}
+	json.Unmarshal(body, &trigger)
+	trigger["id"] = id
+	// The api client can't read back an explicit 'disabled' flag for a trigger it hasn't initialised yet
+	delete(trigger, "disabled")
+	out, _ := json.Marshal(trigger)
+	s.triggers[id] = string(out)
+	s.mu.Unlock()
+
+	w.Write(out)
+}
@@ -0,0 +1,50 @@
+package teamcity
+
+import (
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// buildFeature is a build feature as represented by the REST API, a feature type with its properties.
+// The api client only reads the commit status publisher, so other features are managed through the rest client instead.
+type buildFeature struct {
+	ID         string          `json:"id,omitempty"`
+	Type       string          `json:"type"`
+	Disabled   *bool           `json:"disabled,omitempty"`
+	Inherited  *bool           `json:"inherited,omitempty"`
+	Properties *api.Properties `json:"properties"`
+}
+
+// property returns the value of a feature property, or "" if it isn't set
+func (f *buildFeature) property(name string) string {
+	if f.Properties == nil {
+		return ""
+	}
+	v, _ := f.Properties.GetOk(name)
+	return v
+}
+
+func buildFeaturesPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/features", api.LocatorID(buildConfigID))
+}
+
+func (r *restClient) getBuildFeature(buildConfigID string, id string) (*buildFeature, error) {
+	var out buildFeature
+	if err := r.get(fmt.Sprintf("%s/%s", buildFeaturesPath(buildConfigID), id), &out, "build feature"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *restClient) addBuildFeature(buildConfigID string, f *buildFeature) (*buildFeature, error) {
+	var out buildFeature
+	if err := r.post(buildFeaturesPath(buildConfigID), f, &out, "build feature"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *restClient) deleteBuildFeature(buildConfigID string, id string) error {
+	return r.delete(fmt.Sprintf("%s/%s", buildFeaturesPath(buildConfigID), id), "build feature")
+}
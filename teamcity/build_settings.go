@@ -0,0 +1,60 @@
+package teamcity
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The api client only models some of the build configuration settings, and writes them all at once,
+// resetting the other ones. Those are managed one by one through the rest client instead.
+
+func buildConfigSettingsPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/settings", api.LocatorID(buildConfigID))
+}
+
+// getBuildConfigSettings returns the settings of the build configuration. TeamCity omits the ones with their default value.
+func (r *restClient) getBuildConfigSettings(buildConfigID string) (*api.Properties, error) {
+	var out api.Properties
+	if err := r.get(buildConfigSettingsPath(buildConfigID), &out, "build configuration settings"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// setBuildConfigSettings sets the given settings, keeping the other ones
+func (r *restClient) setBuildConfigSettings(buildConfigID string, settings map[string]string) error {
+	names := make([]string, 0, len(settings))
+	for k := range settings {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		path := fmt.Sprintf("%s/%s", buildConfigSettingsPath(buildConfigID), k)
+		if err := r.putText(path, settings[k], "build configuration setting"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildConfigPausedPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/paused", api.LocatorID(buildConfigID))
+}
+
+// getBuildConfigPaused reports whether the build configuration is paused, which the api client doesn't read
+func (r *restClient) getBuildConfigPaused(buildConfigID string) (bool, error) {
+	v, err := r.getText(buildConfigPausedPath(buildConfigID), "build configuration paused state")
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.TrimSpace(v))
+}
+
+func (r *restClient) setBuildConfigPaused(buildConfigID string, paused bool) error {
+	return r.putText(buildConfigPausedPath(buildConfigID), strconv.FormatBool(paused), "build configuration paused state")
+}
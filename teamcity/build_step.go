@@ -0,0 +1,238 @@
+package teamcity
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// buildStep is a build step as represented by the REST API, a runner type with its properties.
+// The api client only reads the few runner types it models, so steps are managed through the rest client instead.
+type buildStep struct {
+	ID         string          `json:"id,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Type       string          `json:"type"`
+	Disabled   *bool           `json:"disabled,omitempty"`
+	Inherited  *bool           `json:"inherited,omitempty"`
+	Properties *api.Properties `json:"properties"`
+}
+
+type buildSteps struct {
+	Count int          `json:"count"`
+	Items []*buildStep `json:"step"`
+}
+
+// newBuildStep converts a step modelled by the api client, serialising it the way the api client would send it
+func newBuildStep(s api.Step) (*buildStep, error) {
+	dt, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var out buildStep
+	if err := json.Unmarshal(dt, &out); err != nil {
+		return nil, err
+	}
+	if out.Properties == nil {
+		out.Properties = api.NewPropertiesEmpty()
+	}
+	return &out, nil
+}
+
+// newRunnerBuildStep returns a step of the given runner type, with the non-empty properties
+func newRunnerBuildStep(runnerType string, name string, props map[string]string) *buildStep {
+	names := make([]string, 0, len(props))
+	for k := range props {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	p := api.NewPropertiesEmpty()
+	for _, k := range names {
+		if v := props[k]; v != "" {
+			p.AddOrReplaceValue(k, v)
+		}
+	}
+	return &buildStep{
+		Name:       name,
+		Type:       runnerType,
+		Properties: p,
+	}
+}
+
+// decode reads the step into one of the step types modelled by the api client
+func (s *buildStep) decode(out json.Unmarshaler) error {
+	dt, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return out.UnmarshalJSON(dt)
+}
+
+// property returns the value of a runner property, or "" if it isn't set
+func (s *buildStep) property(name string) string {
+	v, _ := s.Properties.GetOk(name)
+	return v
+}
+
+func buildStepsPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/steps", api.LocatorID(buildConfigID))
+}
+
+func (r *restClient) getBuildSteps(buildConfigID string) ([]*buildStep, error) {
+	var out buildSteps
+	if err := r.get(buildStepsPath(buildConfigID), &out, "build steps"); err != nil {
+		return nil, err
+	}
+	for _, s := range out.Items {
+		if s.Properties == nil {
+			s.Properties = api.NewPropertiesEmpty()
+		}
+	}
+	return out.Items, nil
+}
+
+// nonInheritedBuildSteps returns the steps of the build configuration itself, without the ones of its templates
+func nonInheritedBuildSteps(steps []*buildStep) []*buildStep {
+	out := make([]*buildStep, 0, len(steps))
+	for _, s := range steps {
+		if s.Inherited == nil || !*s.Inherited {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (r *restClient) addBuildStep(buildConfigID string, s *buildStep) (*buildStep, error) {
+	var out buildStep
+	if err := r.post(buildStepsPath(buildConfigID), s, &out, "build step"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *restClient) updateBuildStep(buildConfigID string, s *buildStep) (*buildStep, error) {
+	var out buildStep
+	if err := r.put(fmt.Sprintf("%s/%s", buildStepsPath(buildConfigID), s.ID), s, &out, "build step"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// reorderBuildSteps returns the current steps of a build configuration with its own steps in the order of the given ones,
+// matched by id. Inherited steps stay where they are, and the own steps move between the positions own steps occupy.
+// Own steps missing from the order keep their relative order after the others.
+func reorderBuildSteps(current []*buildStep, order []*buildStep) []*buildStep {
+	ordered := make(map[string]bool, len(order))
+	for _, s := range order {
+		ordered[s.ID] = true
+	}
+	byID := make(map[string]*buildStep, len(current))
+	var missing []*buildStep
+	for _, s := range nonInheritedBuildSteps(current) {
+		byID[s.ID] = s
+		if !ordered[s.ID] {
+			missing = append(missing, s)
+		}
+	}
+	own := make([]*buildStep, 0, len(byID))
+	for _, s := range order {
+		if c, ok := byID[s.ID]; ok {
+			own = append(own, c)
+		}
+	}
+	own = append(own, missing...)
+
+	out := make([]*buildStep, 0, len(current))
+	for _, s := range current {
+		if s.Inherited != nil && *s.Inherited {
+			out = append(out, s)
+			continue
+		}
+		out = append(out, own[0])
+		own = own[1:]
+	}
+	return out
+}
+
+// replaceBuildSteps writes the steps of the build configuration, which is how TeamCity reorders them.
+// Steps are matched by id, so writing back the steps as they were read only applies their new order.
+func (r *restClient) replaceBuildSteps(buildConfigID string, steps []*buildStep) error {
+	in := buildSteps{
+		Count: len(steps),
+		Items: steps,
+	}
+	return r.put(buildStepsPath(buildConfigID), &in, nil, "build steps")
+}
+
+func (r *restClient) deleteBuildStep(buildConfigID string, stepID string) error {
+	return r.delete(fmt.Sprintf("%s/%s", buildStepsPath(buildConfigID), stepID), "build step")
+}
+
+// Runner properties common to every step type, which aren't runner parameters
+const (
+	stepModeProperty       = "teamcity.step.mode"
+	stepConditionsProperty = "teamcity.step.conditions"
+)
+
+// stepCondition is an execution condition of a build step, a comparison of a parameter like agent requirements
+type stepCondition struct {
+	Condition string
+	Name      string
+	Value     string
+}
+
+// encodeStepConditions returns the conditions in the format TeamCity stores them, one element per condition
+// named after the comparison, like `<equals name="teamcity.build.branch" value="main" />`
+func encodeStepConditions(conditions []stepCondition) (string, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	for _, c := range conditions {
+		attrs := []xml.Attr{{Name: xml.Name{Local: "name"}, Value: c.Name}}
+		if c.Value != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "value"}, Value: c.Value})
+		}
+		start := xml.StartElement{Name: xml.Name{Local: c.Condition}, Attr: attrs}
+		if err := enc.EncodeToken(start); err != nil {
+			return "", err
+		}
+		if err := enc.EncodeToken(start.End()); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func decodeStepConditions(v string) ([]stepCondition, error) {
+	var out []stepCondition
+	dec := xml.NewDecoder(strings.NewReader(v))
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid step conditions '%s': %s", v, err)
+		}
+		if e, ok := t.(xml.StartElement); ok {
+			c := stepCondition{Condition: e.Name.Local}
+			for _, a := range e.Attr {
+				switch a.Name.Local {
+				case "name":
+					c.Name = a.Value
+				case "value":
+					c.Value = a.Value
+				}
+			}
+			out = append(out, c)
+		}
+	}
+}
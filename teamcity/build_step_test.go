@@ -0,0 +1,261 @@
+package teamcity
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testStepConfig returns a step block as the SDK reads it, where unset attributes hold their default or zero value
+func testStepConfig(values map[string]interface{}) map[string]interface{} {
+	step := resourceBuildConfig().Schema["step"].Elem.(*schema.Resource)
+	out := make(map[string]interface{})
+	for k, s := range step.Schema {
+		if s.Default != nil {
+			out[k] = s.Default
+			continue
+		}
+		switch s.Type {
+		case schema.TypeBool:
+			out[k] = false
+		case schema.TypeList:
+			out[k] = []interface{}{}
+		case schema.TypeMap:
+			out[k] = map[string]interface{}{}
+		default:
+			out[k] = ""
+		}
+	}
+	for k, v := range values {
+		out[k] = v
+	}
+	return out
+}
+
+func TestBuildStep_RoundTrip(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"type": "powershell", "name": "script", "file": "build.ps1", "args": "-Target Release"},
+		{"type": "cmd_line", "name": "code", "code": "echo hello"},
+		{"type": "gradle", "name": "build", "tasks": "clean build", "file": "build.gradle.kts", "working_dir": "app", "jdk_home": "%env.JDK_11%", "use_wrapper": true, "args": "--info"},
+		{"type": "gradle", "name": "default tasks", "use_wrapper": false},
+		{"type": "maven", "name": "package", "goals": "clean package", "file": "pom.xml", "args": "-DskipTests", "maven_version": "3.6.3", "user_settings": "settings.xml", "jdk_home": "%env.JDK_11%"},
+		{"type": "docker", "name": "build", "docker_command": "build", "file": "Dockerfile", "context_dir": "src", "image_tags": []interface{}{"app:1", "app:latest"}, "args": "--pull"},
+		{"type": "docker", "name": "inline", "docker_command": "build", "code": "FROM alpine\n"},
+		{"type": "docker", "name": "push", "docker_command": "push", "image_tags": []interface{}{"app:1"}},
+		{"type": "docker", "name": "tag", "docker_command": "tag", "args": "app:1 app:stable"},
+		{"type": "docker_compose", "name": "services", "compose_files": []interface{}{"docker-compose.yml", "docker-compose.ci.yml"}, "working_dir": "deploy"},
+		{"type": "generic", "name": "kotlin", "runner_type": "kotlinScript", "properties": map[string]interface{}{"kotlinScript.content": "println(1)", "kotlinScript.mode": "CODE"}},
+		{"type": "cmd_line", "name": "cleanup", "code": "rm -rf tmp", "execute_mode": "always", "enabled": false},
+		{"type": "gradle", "name": "report", "tasks": "report", "execute_mode": "on_failure", "conditions": []interface{}{
+			map[string]interface{}{"condition": "equals", "name": "teamcity.build.branch", "value": "main"},
+			map[string]interface{}{"condition": "exists", "name": "env.REPORT_URL", "value": ""},
+		}},
+		{"type": "generic", "name": "deploy", "runner_type": "Ant", "properties": map[string]interface{}{"target": "deploy"}, "execute_mode": "on_success", "conditions": []interface{}{
+			map[string]interface{}{"condition": "does-not-contain", "name": "teamcity.build.branch", "value": "<feature & \"test\">"},
+		}},
+		{"type": "dotnet", "name": "publish", "dotnet_command": "publish", "projects": []interface{}{"src/Api/Api.csproj"}, "framework": "net5.0", "configuration": "Release", "output_dir": "out", "verbosity": "Minimal", "args": "--no-restore"},
+	}
+
+	for _, c := range cases {
+		s, err := expandBuildStep(testStepConfig(c))
+		if err != nil {
+			t.Fatalf("expanding %v: %s", c, err)
+		}
+
+		// Steps are read back from the server as JSON
+		dt, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var read buildStep
+		if err := json.Unmarshal(dt, &read); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := flattenBuildStep(&read)
+		if err != nil {
+			t.Fatalf("flattening %v: %s", c, err)
+		}
+		for k, v := range c {
+			if !reflect.DeepEqual(out[k], v) {
+				t.Errorf("%s step %q: %s = %#v, expected %#v", c["type"], c["name"], k, out[k], v)
+			}
+		}
+
+		// The state is expanded again to update the step in place
+		if _, err := expandBuildStep(testStepConfig(out)); err != nil {
+			t.Errorf("%s step %q: expanding the read step: %s", c["type"], c["name"], err)
+		}
+	}
+}
+
+func TestBuildStep_UnknownRunnerTypeIsGeneric(t *testing.T) {
+	s := newRunnerBuildStep("Ant", "ant", map[string]string{
+		"teamcity.step.mode": "execute_always",
+		"build-file-path":    "build.xml",
+	})
+	out, err := flattenBuildStep(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["type"] != "generic" || out["runner_type"] != "Ant" {
+		t.Errorf("expected a generic step of runner type 'Ant', got type %v and runner type %v", out["type"], out["runner_type"])
+	}
+	expected := map[string]interface{}{"build-file-path": "build.xml"}
+	if !reflect.DeepEqual(out["properties"], expected) {
+		t.Errorf("properties = %v, expected %v", out["properties"], expected)
+	}
+}
+
+func TestBuildStep_GenericRejectsSupportedRunnerType(t *testing.T) {
+	_, err := expandBuildStep(testStepConfig(map[string]interface{}{"type": "generic", "runner_type": "Maven2"}))
+	if err == nil {
+		t.Errorf("expected an error for a generic step of a runner type with a dedicated step type")
+	}
+}
+
+func TestBuildStep_RejectsFieldsOfOtherTypes(t *testing.T) {
+	cases := []struct {
+		step     map[string]interface{}
+		expected string
+	}{
+		{
+			step:     map[string]interface{}{"type": "cmd_line", "name": "build", "code": "make", "tasks": "build"},
+			expected: "tasks is only supported by gradle steps, found in cmd_line step 'build'",
+		},
+		{
+			step:     map[string]interface{}{"type": "gradle", "goals": "package"},
+			expected: "goals is only supported by maven steps, found in a gradle step",
+		},
+		{
+			step:     map[string]interface{}{"type": "maven", "name": "package", "use_wrapper": true},
+			expected: "use_wrapper is only supported by gradle steps, found in maven step 'package'",
+		},
+		{
+			step:     map[string]interface{}{"type": "docker_compose", "name": "up", "compose_files": []interface{}{"docker-compose.yml"}, "docker_command": "build"},
+			expected: "docker_command is only supported by docker steps, found in docker_compose step 'up'",
+		},
+		{
+			step:     map[string]interface{}{"type": "powershell", "name": "script", "code": "Write-Host 1", "working_dir": "src"},
+			expected: "working_dir is only supported by gradle, maven, docker, docker_compose and dotnet steps, found in powershell step 'script'",
+		},
+		{
+			step:     map[string]interface{}{"type": "dotnet", "name": "test", "dotnet_command": "test", "properties": map[string]interface{}{"a": "b"}},
+			expected: "properties is only supported by generic steps, found in dotnet step 'test'",
+		},
+		{
+			step:     map[string]interface{}{"type": "generic", "name": "ant", "runner_type": "Ant", "file": "build.xml"},
+			expected: "file is only supported by powershell, cmd_line, gradle, maven and docker steps, found in generic step 'ant'",
+		},
+		{
+			step:     map[string]interface{}{"type": "docker", "name": "push", "docker_command": "push", "file": "Dockerfile"},
+			expected: "file, code and context_dir are only supported by docker steps running build, found in docker step 'push'",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := expandBuildStep(testStepConfig(c.step))
+		if err == nil {
+			t.Errorf("expected an error for %v", c.step)
+			continue
+		}
+		if err.Error() != c.expected {
+			t.Errorf("error = %q, expected %q", err, c.expected)
+		}
+	}
+}
+
+func TestBuildStep_ConditionValueRequired(t *testing.T) {
+	_, err := expandBuildStep(testStepConfig(map[string]interface{}{
+		"type": "cmd_line",
+		"code": "echo hello",
+		"conditions": []interface{}{
+			map[string]interface{}{"condition": "equals", "name": "teamcity.build.branch", "value": ""},
+		},
+	}))
+	if err == nil {
+		t.Errorf("expected an error for a step condition without value")
+	}
+}
+
+func TestEncodeStepConditions(t *testing.T) {
+	v, err := encodeStepConditions([]stepCondition{
+		{Condition: "equals", Name: "teamcity.build.branch", Value: "main"},
+		{Condition: "exists", Name: "env.DEPLOY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<equals name="teamcity.build.branch" value="main"></equals><exists name="env.DEPLOY"></exists>`
+	if v != expected {
+		t.Errorf("encodeStepConditions = %s, expected %s", v, expected)
+	}
+
+	// TeamCity writes empty elements
+	conditions, err := decodeStepConditions(`<equals name="teamcity.build.branch" value="main" /><exists name="env.DEPLOY" />`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conditions) != 2 || conditions[0].Value != "main" || conditions[1].Condition != "exists" {
+		t.Errorf("unexpected conditions decoded: %v", conditions)
+	}
+}
+
+func TestBuildStep_ConditionsRequireServerVersion(t *testing.T) {
+	steps := []interface{}{
+		testStepConfig(map[string]interface{}{"type": "cmd_line", "name": "build", "code": "make"}),
+		testStepConfig(map[string]interface{}{
+			"type": "cmd_line",
+			"name": "deploy",
+			"code": "make deploy",
+			"conditions": []interface{}{
+				map[string]interface{}{"condition": "equals", "name": "teamcity.build.branch", "value": "main"},
+			},
+		}),
+	}
+
+	cases := []struct {
+		version  ServerVersion
+		expected string
+	}{
+		{ServerVersion{Version: "2020.1 (build 78475)", Major: 2020, Minor: 1}, ""},
+		{ServerVersion{}, ""},
+		{ServerVersion{Version: "2019.2.2 (build 72059)", Major: 2019, Minor: 2}, "cmd_line step 'deploy' has conditions, which require TeamCity 2020.1 or later, but the server runs 2019.2.2 (build 72059)"},
+	}
+	for _, c := range cases {
+		err := validateStepConditionsSupported(steps, c.version)
+		if c.expected == "" {
+			if err != nil {
+				t.Errorf("server %s: unexpected error %q", c.version, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("server %s: error = %v, expected %q", c.version, err, c.expected)
+		}
+	}
+}
+
+func TestReorderBuildSteps(t *testing.T) {
+	inherited := true
+	current := []*buildStep{
+		{ID: "RUNNER_1", Name: "setup", Inherited: &inherited},
+		{ID: "RUNNER_2", Name: "build"},
+		{ID: "RUNNER_3", Name: "test"},
+		{ID: "RUNNER_4", Name: "teardown", Inherited: &inherited},
+		{ID: "RUNNER_5", Name: "publish"},
+	}
+	order := []*buildStep{{ID: "RUNNER_5"}, {ID: "RUNNER_2"}}
+
+	var ids []string
+	for _, s := range reorderBuildSteps(current, order) {
+		ids = append(ids, s.ID)
+	}
+	// Inherited steps stay in place, and the own step missing from the order goes last
+	expected := []string{"RUNNER_1", "RUNNER_5", "RUNNER_2", "RUNNER_4", "RUNNER_3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("reorderBuildSteps = %v, expected %v", ids, expected)
+	}
+}
@@ -0,0 +1,50 @@
+package teamcity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The api client sends some build type requests through `http.DefaultClient`, rather than the client it was created with.
+// Those are sent through the rest client instead, so retries, TLS settings, rate limits and cancellation apply to them.
+
+func buildTypePath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s", api.LocatorID(buildConfigID))
+}
+
+// getBuildType returns the build configuration or template, without the parameters it inherits, like the api client does
+func (r *restClient) getBuildType(buildConfigID string) (*api.BuildType, error) {
+	var out api.BuildType
+	if err := r.get(buildTypePath(buildConfigID), &out, "build type"); err != nil {
+		return nil, err
+	}
+	out.Parameters = out.Parameters.NonInherited()
+	return &out, nil
+}
+
+// updateBuildType writes the name, description and settings of the build configuration.
+// Unlike the api client it doesn't write the steps, which are updated one by one with updateBuildSteps,
+// nor the parameters, which are written with their type specification by replaceParameters.
+func (r *restClient) updateBuildType(dt *api.BuildType) error {
+	if err := r.putText(buildTypePath(dt.ID)+"/name", dt.Name, "build type name"); err != nil {
+		return err
+	}
+	if err := r.putText(buildTypePath(dt.ID)+"/description", dt.Description, "build type description"); err != nil {
+		return err
+	}
+
+	// The api client only exposes the settings of the options through the build type payload
+	raw, err := json.Marshal(dt)
+	if err != nil {
+		return err
+	}
+	var payload struct {
+		Settings json.RawMessage `json:"settings"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return err
+	}
+	return r.put(buildConfigSettingsPath(dt.ID), payload.Settings, nil, "build type settings")
+}
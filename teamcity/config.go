@@ -1,7 +1,18 @@
 package teamcity
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
 )
@@ -12,18 +23,288 @@ type Config struct {
 	Token    string
 	Username string
 	Password string
+
+	// MaxRetries is how many times a request is retried when the server is temporarily unavailable. Zero disables retries.
+	MaxRetries int
+	// RetryWaitMin is the wait before the first retry. Following waits double, up to RetryWaitMax.
+	RetryWaitMin time.Duration
+	// RetryWaitMax is the longest wait between two retries.
+	RetryWaitMax time.Duration
+
+	// MaxRequestsPerSecond limits the rate of requests sent to the server. Zero means unlimited.
+	MaxRequestsPerSecond float64
+	// MaxConcurrentRequests limits how many requests are in flight at the same time. Zero means unlimited.
+	MaxConcurrentRequests int
+
+	// CACertFile is the path to a PEM-encoded CA bundle used to verify the server certificate.
+	CACertFile string
+	// CACertPEM is a PEM-encoded CA bundle used to verify the server certificate.
+	CACertPEM string
+	// ClientCert is the PEM-encoded client certificate for mutual TLS, or the path to a file containing it.
+	ClientCert string
+	// ClientKey is the PEM-encoded private key for ClientCert, or the path to a file containing it.
+	ClientKey string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// Meta is passed to every resource and data source. It holds the api client along with details of the server it talks to.
+type Meta struct {
+	Client *api.Client
+
+	// ServerVersion is detected when the provider is configured
+	ServerVersion ServerVersion
+
+	// buildConfigLocks serialises changes to the same build configuration, made by the resources nested in it
+	buildConfigLocks *mutexKV
+
+	newClient     func(httpClient *http.Client) (*api.Client, error)
+	newRestClient func(httpClient *http.Client) *restClient
+}
+
+// client returns an api client sending its requests with ctx, so they are cancelled along with the operation using it
+func (m *Meta) client(ctx context.Context) *api.Client {
+	client, err := m.newClient(&http.Client{
+		Transport: newContextTransport(m.Client.HTTPClient.Transport, ctx),
+	})
+	if err != nil {
+		// Can't happen, as the same settings were used to create m.Client
+		return m.Client
+	}
+	return client
+}
+
+// rest returns a client for the REST endpoints the api client doesn't cover, sending its requests with ctx
+func (m *Meta) rest(ctx context.Context) *restClient {
+	return m.newRestClient(&http.Client{
+		Transport: newContextTransport(m.Client.HTTPClient.Transport, ctx),
+	})
+}
+
+// lockBuildConfig holds the lock for a build configuration until the returned function is called.
+// Changes to a build configuration and its triggers, dependencies, requirements and features must hold it,
+// as TeamCity doesn't handle concurrent edits of the same build configuration reliably.
+func (m *Meta) lockBuildConfig(id string) func() {
+	m.buildConfigLocks.Lock(id)
+	return func() {
+		m.buildConfigLocks.Unlock(id)
+	}
+}
+
+// ServerVersion identifies the TeamCity server release, like "2019.2.2 (build 72059)"
+type ServerVersion struct {
+	Version string
+	Major   int
+	Minor   int
+}
+
+// IsKnown returns false if the server didn't disclose its version to the configured user
+func (v ServerVersion) IsKnown() bool {
+	return v.Major > 0
+}
+
+// AtLeast returns true if the server is running the given release or a newer one.
+// It assumes a recent server if the version is unknown.
+func (v ServerVersion) AtLeast(major int, minor int) bool {
+	if !v.IsKnown() {
+		return true
+	}
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v ServerVersion) String() string {
+	if !v.IsKnown() {
+		return "unknown"
+	}
+	return v.Version
 }
 
 // Client Returns a new TeamCity api client configured with this instance parameters
 func (c *Config) Client() (*api.Client, error) {
-	// `http.DefaultClient` doesn't configure a proxy by default - this does
-	httpClient := &http.Client{
-		Transport: http.DefaultTransport,
+	base, err := c.baseTransport()
+	if err != nil {
+		return nil, err
 	}
 
+	// `http.DefaultClient` doesn't configure a proxy by default - this does
+	return c.newClient(&http.Client{
+		Transport: c.transport(base),
+	})
+}
+
+func (c *Config) newClient(httpClient *http.Client) (*api.Client, error) {
 	if c.Token != "" {
 		return api.NewClientWithAddress(api.TokenAuth(c.Token), c.Address, httpClient)
 	}
 
 	return api.NewClientWithAddress(api.BasicAuth(c.Username, c.Password), c.Address, httpClient)
 }
+
+// Meta returns the provider meta, after checking the server can be reached with the configured credentials
+func (c *Config) Meta(ctx context.Context) (*Meta, error) {
+	client, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := c.serverVersion(ctx, client.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Connected to TeamCity server at '%s', version: %s", c.Address, version)
+
+	return &Meta{
+		Client:           client,
+		ServerVersion:    *version,
+		buildConfigLocks: newMutexKV(),
+		newClient:        c.newClient,
+		newRestClient:    c.newRestClient,
+	}, nil
+}
+
+// serverVersion calls the server info endpoint, turning connectivity and authentication problems into clear errors
+func (c *Config) serverVersion(ctx context.Context, httpClient *http.Client) (*ServerVersion, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.restURL("server"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TeamCity address '%s': %s", c.Address, err)
+	}
+	c.authorize(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to TeamCity server at '%s': %s", c.Address, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("authentication with TeamCity server at '%s' failed: check the `token`, or `username` and `password` provider arguments", c.Address)
+	case http.StatusForbidden:
+		log.Printf("[WARN] The configured user is not allowed to read server information from '%s', server version is unknown", c.Address)
+		return &ServerVersion{}, nil
+	default:
+		return nil, fmt.Errorf("unexpected response '%s' from TeamCity server at '%s'", resp.Status, c.Address)
+	}
+
+	var server api.Server
+	if err := json.NewDecoder(resp.Body).Decode(&server); err != nil || server.Version == "" {
+		return nil, fmt.Errorf("address '%s' doesn't look like a TeamCity server: unable to read server information", c.Address)
+	}
+
+	return &ServerVersion{
+		Version: server.Version,
+		Major:   int(server.VersionMajor),
+		Minor:   int(server.VersionMinor),
+	}, nil
+}
+
+// restURL returns the REST API url for path, matching the authentication scheme in use
+func (c *Config) restURL(path string) string {
+	base := strings.TrimSuffix(c.Address, "/")
+	if c.Token != "" {
+		return base + "/app/rest/" + path
+	}
+	return base + "/httpAuth/app/rest/" + path
+}
+
+func (c *Config) authorize(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+}
+
+func (c *Config) transport(base http.RoundTripper) http.RoundTripper {
+	t := base
+	// Limits apply to every attempt, so retries are throttled as well
+	if c.MaxRequestsPerSecond > 0 || c.MaxConcurrentRequests > 0 {
+		t = newLimitTransport(t, c.MaxRequestsPerSecond, c.MaxConcurrentRequests)
+	}
+	if c.MaxRetries > 0 {
+		t = newRetryTransport(t, c.MaxRetries, c.RetryWaitMin, c.RetryWaitMax)
+	}
+	return t
+}
+
+// baseTransport returns the transport used to reach the server, with any custom TLS settings applied
+func (c *Config) baseTransport() (http.RoundTripper, error) {
+	if c.CACertFile == "" && c.CACertPEM == "" && c.ClientCert == "" && c.ClientKey == "" && !c.InsecureSkipVerify {
+		return http.DefaultTransport, nil
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Same settings as `http.DefaultTransport`, which can't be cloned since the api client replaces it with a logging transport
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}, nil
+}
+
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACertFile != "" || c.CACertPEM != "" {
+		caCert := []byte(c.CACertPEM)
+		if c.CACertFile != "" {
+			var err error
+			if caCert, err = ioutil.ReadFile(c.CACertFile); err != nil {
+				return nil, fmt.Errorf("error reading CA certificate file '%s': %s", c.CACertFile, err)
+			}
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("no valid PEM-encoded certificates found in CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New("both a client certificate and a client key must be specified for mutual TLS")
+		}
+		cert, err := readPEMOrFile(c.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %s", err)
+		}
+		key, err := readPEMOrFile(c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client key: %s", err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEMOrFile returns v if it's PEM-encoded content, otherwise reads the file it points to
+func readPEMOrFile(v string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(v), "-----BEGIN") {
+		return []byte(v), nil
+	}
+	return ioutil.ReadFile(v)
+}
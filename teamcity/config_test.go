@@ -0,0 +1,429 @@
+package teamcity_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+)
+
+func TestConfigClient_RetriesUnavailableServer(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := testConfigHTTPClient(t, srv.URL, 3)
+	resp, err := client.Get(srv.URL + "/app/rest/server")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestConfigClient_RetriesRewindBody(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("expected body 'payload', got '%s'", body)
+		}
+		if atomic.AddInt32(&calls, 1) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := testConfigHTTPClient(t, srv.URL, 3)
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/app/rest/projects/id:Test/description", strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestConfigClient_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := testConfigHTTPClient(t, srv.URL, 2)
+	resp, err := client.Get(srv.URL + "/app/rest/server")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestConfigClient_DoesNotRetryNonIdempotentRequests(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := testConfigHTTPClient(t, srv.URL, 3)
+	resp, err := client.Post(srv.URL+"/app/rest/projects", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func testConfigHTTPClient(t *testing.T, address string, maxRetries int) *http.Client {
+	config := teamcity.Config{
+		Address:      address,
+		Token:        "token",
+		MaxRetries:   maxRetries,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 5 * time.Millisecond,
+	}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	return client.HTTPClient
+}
+
+func TestConfigClient_CustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if _, err := testTLSConfigHTTPClient(t, teamcity.Config{Address: srv.URL}).Get(srv.URL); err == nil {
+		t.Fatalf("expected certificate verification to fail without the custom CA")
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	resp, err := testTLSConfigHTTPClient(t, teamcity.Config{Address: srv.URL, CACertPEM: string(caPEM)}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestConfigClient_InsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	resp, err := testTLSConfigHTTPClient(t, teamcity.Config{Address: srv.URL, InsecureSkipVerify: true}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestConfigClient_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM := testGenerateCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certPEM)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := testTLSConfigHTTPClient(t, teamcity.Config{Address: srv.URL, InsecureSkipVerify: true}).Get(srv.URL); err == nil {
+		t.Fatalf("expected request without a client certificate to fail")
+	}
+
+	keyFile, err := ioutil.TempFile("", "client.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(keyFile.Name())
+	if _, err := keyFile.Write(keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	keyFile.Close()
+
+	config := teamcity.Config{
+		Address:            srv.URL,
+		InsecureSkipVerify: true,
+		ClientCert:         string(certPEM),
+		ClientKey:          keyFile.Name(),
+	}
+	resp, err := testTLSConfigHTTPClient(t, config).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestConfigClient_ClientCertificateWithoutKey(t *testing.T) {
+	certPEM, _ := testGenerateCertificate(t)
+	config := teamcity.Config{Address: "https://teamcity", Token: "token", ClientCert: string(certPEM)}
+
+	if _, err := config.Client(); err == nil {
+		t.Fatalf("expected an error when client_key is missing")
+	}
+}
+
+func testTLSConfigHTTPClient(t *testing.T, config teamcity.Config) *http.Client {
+	config.Token = "token"
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	return client.HTTPClient
+}
+
+func testGenerateCertificate(t *testing.T) (certPEM []byte, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return certPEM, keyPEM
+}
+
+func TestConfigClient_LimitsConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := testLimitedConfigHTTPClient(t, srv.URL, 0, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL + "/app/rest/server")
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
+
+func TestConfigClient_LimitsRequestRate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := testLimitedConfigHTTPClient(t, srv.URL, 50, 0)
+	start := time.Now()
+	// The first 50 requests are allowed in a burst, the next 10 take at least 200ms at 50 requests per second
+	for i := 0; i < 60; i++ {
+		resp, err := client.Get(srv.URL + "/app/rest/server")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestConfigClient_RetriesThrottledRequests(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := testConfigHTTPClient(t, srv.URL, 3)
+	resp, err := client.Post(srv.URL+"/app/rest/projects", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("expected the throttled request to be retried once, got status %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func testLimitedConfigHTTPClient(t *testing.T, address string, requestsPerSecond float64, maxConcurrent int) *http.Client {
+	config := teamcity.Config{
+		Address:               address,
+		Token:                 "token",
+		MaxRequestsPerSecond:  requestsPerSecond,
+		MaxConcurrentRequests: maxConcurrent,
+	}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	return client.HTTPClient
+}
+
+func TestConfigMeta_DetectsServerVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/rest/server" {
+			t.Errorf("unexpected request to '%s'", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version": "2019.2.2 (build 72059)", "versionMajor": 2019, "versionMinor": 2}`))
+	}))
+	defer srv.Close()
+
+	meta, err := (&teamcity.Config{Address: srv.URL, Token: "token"}).Meta(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	v := meta.ServerVersion
+	if v.Version != "2019.2.2 (build 72059)" || v.Major != 2019 || v.Minor != 2 {
+		t.Fatalf("unexpected server version: %+v", v)
+	}
+	if !v.AtLeast(2019, 2) || !v.AtLeast(2018, 9) || v.AtLeast(2019, 3) || v.AtLeast(2020, 1) {
+		t.Fatalf("unexpected version comparison results for %s", v)
+	}
+}
+
+func TestConfigMeta_InvalidCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := (&teamcity.Config{Address: srv.URL, Username: "admin", Password: "wrong"}).Meta(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "authentication") {
+		t.Fatalf("expected an authentication error, got: %v", err)
+	}
+}
+
+func TestConfigMeta_UnreachableServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	address := srv.URL
+	srv.Close()
+
+	_, err := (&teamcity.Config{Address: address, Token: "token"}).Meta(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "error connecting") {
+		t.Fatalf("expected a connection error, got: %v", err)
+	}
+}
+
+func TestConfigMeta_NotATeamCityServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>Welcome</html>"))
+	}))
+	defer srv.Close()
+
+	_, err := (&teamcity.Config{Address: srv.URL, Token: "token"}).Meta(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "doesn't look like a TeamCity server") {
+		t.Fatalf("expected a server detection error, got: %v", err)
+	}
+}
+
+func TestConfigMeta_VersionNotVisible(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	meta, err := (&teamcity.Config{Address: srv.URL, Token: "token"}).Meta(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if meta.ServerVersion.IsKnown() || !meta.ServerVersion.AtLeast(2020, 1) {
+		t.Fatalf("expected an unknown server version, got: %+v", meta.ServerVersion)
+	}
+}
@@ -1,15 +1,17 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 
-	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceAgentPool() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceAgentPoolRead,
+		ReadContext: dataSourceAgentPoolRead,
+		Timeouts:    dataSourceTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -23,13 +25,13 @@ func dataSourceAgentPool() *schema.Resource {
 	}
 }
 
-func dataSourceAgentPoolRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func dataSourceAgentPoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	name := d.Get("name").(string)
 	agentPool, err := client.AgentPools.GetByName(name)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(fmt.Sprintf("%d", agentPool.Id))
@@ -1,15 +1,17 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceProject() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceProjectRead,
+		ReadContext: dataSourceProjectRead,
+		Timeouts:    dataSourceTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"project_id": {
 				Type:          schema.TypeString,
@@ -36,8 +38,8 @@ func dataSourceProject() *schema.Resource {
 	}
 }
 
-func dataSourceProjectRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func dataSourceProjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var id, name string
 	var dt *api.Project
 
@@ -50,19 +52,19 @@ func dataSourceProjectRead(d *schema.ResourceData, meta interface{}) error {
 	if id != "" {
 		p, err := client.Projects.GetByID(id)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		dt = p
 	}
 	if name != "" {
 		p, err := client.Projects.GetByName(name)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		dt = p
 	}
 	if dt == nil {
-		return fmt.Errorf("error when retrieving project, either `project_id` or `name` are required to be set")
+		return diag.Errorf("error when retrieving project, either `project_id` or `name` are required to be set")
 	}
 	d.SetId(dt.ID)
 	d.Set("name", dt.Name)
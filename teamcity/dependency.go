@@ -0,0 +1,45 @@
+package teamcity
+
+import (
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The api client sends the requests creating dependencies and reading snapshot dependencies through `http.DefaultClient`,
+// so they are sent through the rest client instead.
+
+func snapshotDependenciesPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/snapshot-dependencies", api.LocatorID(buildConfigID))
+}
+
+func artifactDependenciesPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/artifact-dependencies", api.LocatorID(buildConfigID))
+}
+
+func (r *restClient) addSnapshotDependency(buildConfigID string, dep *api.SnapshotDependency) (*api.SnapshotDependency, error) {
+	var out api.SnapshotDependency
+	if err := r.post(snapshotDependenciesPath(buildConfigID), dep, &out, "snapshot dependency"); err != nil {
+		return nil, err
+	}
+	out.BuildTypeID = buildConfigID
+	return &out, nil
+}
+
+func (r *restClient) getSnapshotDependency(buildConfigID string, id string) (*api.SnapshotDependency, error) {
+	var out api.SnapshotDependency
+	if err := r.get(fmt.Sprintf("%s/%s", snapshotDependenciesPath(buildConfigID), id), &out, "snapshot dependency"); err != nil {
+		return nil, err
+	}
+	out.BuildTypeID = buildConfigID
+	return &out, nil
+}
+
+func (r *restClient) addArtifactDependency(buildConfigID string, dep *api.ArtifactDependency) (*api.ArtifactDependency, error) {
+	var out api.ArtifactDependency
+	if err := r.post(artifactDependenciesPath(buildConfigID), dep, &out, "artifact dependency"); err != nil {
+		return nil, err
+	}
+	out.SetBuildTypeID(buildConfigID)
+	return &out, nil
+}
@@ -0,0 +1,50 @@
+package teamcity
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// notFoundErrorPatterns match the errors returned by the api client when TeamCity answers with '404 Not Found'.
+// Unlike the rest client, the api client doesn't return typed errors, and the message depends on the service that made the request.
+var notFoundErrorPatterns = []*regexp.Regexp{
+	// Most services, like projects, agent pools, groups, triggers and artifact dependencies
+	regexp.MustCompile(`^Error '404' when `),
+	// Build configurations and VCS roots
+	regexp.MustCompile(`^Error when retrieving \w+ id = '.*', status: 404$`),
+	// Agent requirements, build features and snapshot dependencies
+	regexp.MustCompile(`^404 Not Found`),
+}
+
+// isNotFoundError returns true if err reports that the requested object doesn't exist on the server.
+// Read functions use it to remove objects deleted outside of Terraform from the state, so they are planned for creation.
+func isNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var re *requestError
+	if errors.As(err, &re) {
+		return re.StatusCode == http.StatusNotFound
+	}
+	for _, p := range notFoundErrorPatterns {
+		if p.MatchString(err.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeErrorf returns an error diagnostic pointing at a top level attribute of the resource,
+// for configuration errors that can only be detected when talking to the server
+func attributeErrorf(attribute string, format string, args ...interface{}) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       fmt.Sprintf(format, args...),
+		AttributePath: cty.GetAttrPath(attribute),
+	}}
+}
@@ -0,0 +1,49 @@
+package teamcity
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestIsNotFoundError(t *testing.T) {
+	cases := []struct {
+		err      string
+		notFound bool
+	}{
+		{"Error '404' when performing 'GET' operation - project: Responding with error, status code: 404 (Not Found).", true},
+		{"Error '404' when deleting trigger: Nothing is found by locator", true},
+		{"Error when retrieving BuildType id = 'Project_Build', status: 404", true},
+		{"Error when retrieving VcsRoot id = 'Project_Repo', status: 404", true},
+		{"404 Not Found - Build feature (id: BUILD_EXT_1) for buildTypeId (id: Project_Build) was not found", true},
+		{"404 Not Found - Snapshot dependency (id: Project_Dep) for buildTypeId (id: Project_Build) was not found", true},
+		{"Error '500' when performing 'GET' operation - project: Internal Server Error", false},
+		{"Error when retrieving BuildType id = 'Project_404', status: 403", false},
+		{"Error '403' when performing 'GET' operation - group: You do not have permission 404", false},
+	}
+
+	for _, c := range cases {
+		if got := isNotFoundError(errors.New(c.err)); got != c.notFound {
+			t.Errorf("isNotFoundError(%q) = %t, expected %t", c.err, got, c.notFound)
+		}
+	}
+	if isNotFoundError(nil) {
+		t.Errorf("isNotFoundError(nil) = true, expected false")
+	}
+}
+
+func TestIsNotFoundError_RequestError(t *testing.T) {
+	notFound := &requestError{StatusCode: http.StatusNotFound, Method: http.MethodGet, ResourceDescription: "build steps"}
+	if !isNotFoundError(notFound) {
+		t.Errorf("expected a 404 request error to be a not found error")
+	}
+	if !isNotFoundError(fmt.Errorf("reading build steps: %w", notFound)) {
+		t.Errorf("expected a wrapped 404 request error to be a not found error")
+	}
+	// The status code decides, not the message
+	forbidden := &requestError{StatusCode: http.StatusForbidden, Method: http.MethodGet, ResourceDescription: "build steps", Body: "404 Not Found"}
+	if isNotFoundError(forbidden) {
+		t.Errorf("expected a 403 request error not to be a not found error")
+	}
+}
@@ -0,0 +1,1532 @@
+package teamcity_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestMain runs the acceptance tests against an in-memory fake of the TeamCity REST API when TEAMCITY_FAKE is set,
+// so they can run without the server from `integration_tests/docker-compose.yml`:
+//
+//	TEAMCITY_FAKE=1 go test ./teamcity
+func TestMain(m *testing.M) {
+	if os.Getenv("TEAMCITY_FAKE") == "" {
+		os.Exit(m.Run())
+	}
+
+	srv := newFakeServer()
+	os.Setenv("TEAMCITY_ADDR", srv.URL)
+	os.Setenv("TEAMCITY_TOKEN", "fake")
+	os.Setenv("TF_ACC", "1")
+	code := m.Run()
+	srv.Close()
+	os.Exit(code)
+}
+
+// fakeRootURL is the root URL the fake server reports for web links, matching the server used by the acceptance tests
+const fakeRootURL = "http://127.0.0.1:8112"
+
+type fakeObject = map[string]interface{}
+
+// fakeServer is an in-memory implementation of the parts of the TeamCity REST API used by the provider.
+// It keeps the behaviours the provider depends on, like ID generation from names and default values being omitted,
+// but doesn't validate payloads any further than the provider needs.
+type fakeServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	projects   map[string]*fakeProject
+	buildTypes map[string]*fakeBuildType
+	vcsRoots   map[string]*fakeVcsRoot
+	agentPools map[int]*fakeAgentPool
+	groups     map[string]*fakeGroup
+	// lastStepID numbers the steps of all build types, since the ids of their own steps and of the ones
+	// inherited from their templates can't clash
+	lastStepID int
+}
+
+type fakeProject struct {
+	ID                string
+	Name              string
+	Description       string
+	ParentID          string
+	Parameters        []fakeObject
+	DefaultTemplateID string
+	// BuildTypeOrder and ProjectOrder hold the IDs of the children shown first, in order
+	BuildTypeOrder []string
+	ProjectOrder   []string
+}
+
+type fakeBuildType struct {
+	ID          string
+	ProjectID   string
+	Name        string
+	Description string
+	Template    bool
+	Paused      bool
+	Settings    []fakeObject
+	Parameters  []fakeObject
+	// Children holds the collections nested in the build type, like steps and triggers, by their path
+	Children map[string][]fakeObject
+	lastIDs  map[string]int
+}
+
+type fakeVcsRoot struct {
+	ID                        string
+	Name                      string
+	ProjectID                 string
+	VcsName                   string
+	ModificationCheckInterval int
+	Properties                []fakeObject
+}
+
+type fakeAgentPool struct {
+	ID        int
+	Name      string
+	MaxAgents *int
+	Projects  []string
+}
+
+type fakeGroup struct {
+	Key         string
+	Name        string
+	Description string
+	Roles       []fakeObject
+}
+
+// fakeBuildTypeCollections describes the collections nested in a build type: the JSON key of their items,
+// and the prefix of the IDs generated for new items. Items without a prefix are identified by what they reference.
+var fakeBuildTypeCollections = map[string]struct {
+	item     string
+	idPrefix string
+}{
+	"steps":                 {"step", "RUNNER_"},
+	"triggers":              {"trigger", "TRIGGER_"},
+	"features":              {"feature", "BUILD_EXT_"},
+	"agent-requirements":    {"agent-requirement", "RQ_"},
+	"artifact-dependencies": {"artifact-dependency", "ARTIFACT_DEPENDENCY_"},
+	"snapshot-dependencies": {"snapshot-dependency", ""},
+	"vcs-root-entries":      {"vcs-root-entry", ""},
+	"templates":             {"buildType", ""},
+}
+
+type fakeRequest struct {
+	method string
+	path   []string
+	query  url.Values
+	body   []byte
+}
+
+type fakeResponse struct {
+	status int
+	// body is sent as plain text if it's a string, as JSON otherwise
+	body interface{}
+}
+
+func newFakeServer() *fakeServer {
+	s := &fakeServer{
+		projects: map[string]*fakeProject{
+			"_Root": {ID: "_Root", Name: "<Root project>"},
+		},
+		buildTypes: make(map[string]*fakeBuildType),
+		vcsRoots:   make(map[string]*fakeVcsRoot),
+		agentPools: map[int]*fakeAgentPool{
+			0: {ID: 0, Name: "Default", Projects: []string{"_Root"}},
+		},
+		groups: make(map[string]*fakeGroup),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/httpAuth")
+	req := &fakeRequest{
+		method: r.Method,
+		path:   strings.Split(strings.Trim(strings.TrimPrefix(path, "/app/rest/"), "/"), "/"),
+		query:  r.URL.Query(),
+		body:   body,
+	}
+
+	var resp fakeResponse
+	switch {
+	case !strings.HasPrefix(path, "/app/rest/"):
+		resp = fakeNotFound("Unknown path '%s'", r.URL.Path)
+	case req.path[0] == "server":
+		resp = fakeJSON(fakeObject{
+			"version":      "2020.1 (build 78475)",
+			"versionMajor": 2020,
+			"versionMinor": 1,
+			"buildNumber":  "78475",
+			"webUrl":       fakeRootURL,
+		})
+	case req.path[0] == "projects":
+		resp = s.serveProjects(req)
+	case req.path[0] == "buildTypes":
+		resp = s.serveBuildTypes(req)
+	case req.path[0] == "vcs-roots":
+		resp = s.serveVcsRoots(req)
+	case req.path[0] == "agentPools":
+		resp = s.serveAgentPools(req)
+	case req.path[0] == "userGroups":
+		resp = s.serveGroups(req)
+	default:
+		resp = fakeNotFound("Unknown path '%s'", r.URL.Path)
+	}
+
+	if text, ok := resp.body.(string); ok {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(resp.status)
+		w.Write([]byte(text))
+		return
+	}
+	if resp.body == nil {
+		w.WriteHeader(resp.status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	json.NewEncoder(w).Encode(resp.body)
+}
+
+func fakeJSON(v interface{}) fakeResponse {
+	return fakeResponse{status: http.StatusOK, body: v}
+}
+
+func fakeText(v string) fakeResponse {
+	return fakeResponse{status: http.StatusOK, body: v}
+}
+
+func fakeNoContent() fakeResponse {
+	return fakeResponse{status: http.StatusNoContent}
+}
+
+func fakeError(status int, format string, args ...interface{}) fakeResponse {
+	return fakeResponse{
+		status: status,
+		body:   fmt.Sprintf("Responding with error, status code: %d (%s).\nDetails: %s", status, http.StatusText(status), fmt.Sprintf(format, args...)),
+	}
+}
+
+func fakeNotFound(format string, args ...interface{}) fakeResponse {
+	return fakeError(http.StatusNotFound, format, args...)
+}
+
+func fakeBadRequest(format string, args ...interface{}) fakeResponse {
+	return fakeError(http.StatusBadRequest, format, args...)
+}
+
+func fakeMethodNotAllowed(r *fakeRequest) fakeResponse {
+	return fakeError(http.StatusMethodNotAllowed, "%s is not supported on '%s'", r.method, strings.Join(r.path, "/"))
+}
+
+// fakeLocator returns the value of dimension in a locator like `id:Project` or `name:My Project`.
+// A locator without dimension, like `Project`, is matched against defaultDimension.
+func fakeLocator(locator string, dimension string, defaultDimension string) (string, bool) {
+	if strings.HasPrefix(locator, dimension+":") {
+		return strings.TrimPrefix(locator, dimension+":"), true
+	}
+	if dimension == defaultDimension && !strings.Contains(locator, ":") {
+		return locator, true
+	}
+	return "", false
+}
+
+var fakeIDSeparator = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// fakeGenerateID generates an ID from a name, like TeamCity does: "Build Release" in project "Project" gets "Project_BuildRelease"
+func fakeGenerateID(parentID string, name string, exists func(string) bool) string {
+	var id strings.Builder
+	for _, word := range fakeIDSeparator.Split(name, -1) {
+		if word != "" {
+			id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	base := id.String()
+	if parentID != "" && parentID != "_Root" {
+		base = parentID + "_" + base
+	}
+
+	generated := base
+	for i := 2; exists(generated); i++ {
+		generated = fmt.Sprintf("%s%d", base, i)
+	}
+	return generated
+}
+
+func fakeDecode(r *fakeRequest) (fakeObject, error) {
+	var out fakeObject
+	if err := json.Unmarshal(r.body, &out); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %s", err)
+	}
+	return out, nil
+}
+
+func fakeString(o fakeObject, keys ...string) string {
+	for i, k := range keys {
+		v, ok := o[k]
+		if !ok {
+			return ""
+		}
+		if i < len(keys)-1 {
+			if o, ok = v.(fakeObject); !ok {
+				return ""
+			}
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(v)
+		}
+	}
+	return ""
+}
+
+// fakeItems returns the items of a collection like `{"count": 1, "property": [...]}`
+func fakeItems(o fakeObject, key string) []fakeObject {
+	raw, _ := o[key].([]interface{})
+	out := make([]fakeObject, 0, len(raw))
+	for _, r := range raw {
+		if item, ok := r.(fakeObject); ok {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func fakeCollection(key string, items []fakeObject) fakeObject {
+	if items == nil {
+		items = []fakeObject{}
+	}
+	return fakeObject{"count": len(items), key: items}
+}
+
+// fakeNormalize removes the flags TeamCity omits when they have their default value
+func fakeNormalize(item fakeObject) fakeObject {
+	for _, flag := range []string{"disabled", "inherited"} {
+		if v, ok := item[flag].(bool); ok && !v {
+			delete(item, flag)
+		}
+	}
+	return item
+}
+
+// fakeProperties returns the properties as TeamCity does, without the values of passwords
+func fakeProperties(props []fakeObject) fakeObject {
+	out := make([]fakeObject, 0, len(props))
+	for _, p := range props {
+		prop := fakeObject{}
+		for k, v := range p {
+			prop[k] = v
+		}
+		if strings.HasPrefix(fakeString(p, "type", "rawValue"), "password") {
+			prop["value"] = ""
+		}
+		out = append(out, prop)
+	}
+	return fakeCollection("property", out)
+}
+
+func fakeSetProperty(props []fakeObject, name string, value string) []fakeObject {
+	for _, p := range props {
+		if fakeString(p, "name") == name {
+			p["value"] = value
+			return props
+		}
+	}
+	return append(props, fakeObject{"name": name, "value": value})
+}
+
+// serveParameters handles the parameters of a project or build type, at r.path[idx:]
+// Reads return the effective parameters, the own ones and the inherited ones they don't override
+func serveFakeParameters(r *fakeRequest, idx int, params *[]fakeObject, effective []fakeObject) fakeResponse {
+	if len(r.path) == idx {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(fakeProperties(effective))
+		case http.MethodPut:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			*params = fakeItems(body, "property")
+			return fakeJSON(fakeProperties(*params))
+		case http.MethodPost:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			*params = fakeReplaceParameter(*params, body)
+			return fakeJSON(body)
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	name := r.path[idx]
+	var param fakeObject
+	for _, p := range *params {
+		if fakeString(p, "name") == name {
+			param = p
+		}
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		for _, p := range effective {
+			if fakeString(p, "name") == name {
+				param = p
+			}
+		}
+		if param == nil {
+			return fakeNotFound("No parameter with name '%s' is found", name)
+		}
+		return fakeJSON(fakeProperties([]fakeObject{param})["property"].([]fakeObject)[0])
+	case http.MethodPut:
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		body["name"] = name
+		*params = fakeReplaceParameter(*params, body)
+		return fakeJSON(body)
+	case http.MethodDelete:
+		if param == nil {
+			return fakeNotFound("No parameter with name '%s' is found", name)
+		}
+		out := (*params)[:0]
+		for _, p := range *params {
+			if fakeString(p, "name") != name {
+				out = append(out, p)
+			}
+		}
+		*params = out
+		return fakeNoContent()
+	}
+	return fakeMethodNotAllowed(r)
+}
+
+// fakeInheritParameters returns the own parameters followed by the inherited ones they don't override, flagged as inherited
+func fakeInheritParameters(own []fakeObject, inherited []fakeObject) []fakeObject {
+	out := append([]fakeObject{}, own...)
+	for _, p := range inherited {
+		overridden := false
+		for _, o := range out {
+			if fakeString(o, "name") == fakeString(p, "name") {
+				overridden = true
+			}
+		}
+		if overridden {
+			continue
+		}
+		param := fakeObject{"inherited": true}
+		for k, v := range p {
+			if k != "inherited" {
+				param[k] = v
+			}
+		}
+		out = append(out, param)
+	}
+	return out
+}
+
+// projectParameters returns the effective parameters of the project, including the ones of its parents
+func (s *fakeServer) projectParameters(p *fakeProject) []fakeObject {
+	if p == nil {
+		return nil
+	}
+	return fakeInheritParameters(p.Parameters, s.projectParameters(s.projects[p.ParentID]))
+}
+
+// buildTypeParameters returns the effective parameters of the build type, including the ones of its templates and projects
+func (s *fakeServer) buildTypeParameters(bt *fakeBuildType) []fakeObject {
+	var inherited []fakeObject
+	for _, t := range bt.Children["templates"] {
+		if template := s.buildTypes[fakeString(t, "id")]; template != nil {
+			inherited = fakeInheritParameters(inherited, template.Parameters)
+		}
+	}
+	inherited = fakeInheritParameters(inherited, s.projectParameters(s.projects[bt.ProjectID]))
+	return fakeInheritParameters(bt.Parameters, inherited)
+}
+
+func fakeReplaceParameter(params []fakeObject, param fakeObject) []fakeObject {
+	for i, p := range params {
+		if fakeString(p, "name") == fakeString(param, "name") {
+			params[i] = param
+			return params
+		}
+	}
+	return append(params, param)
+}
+
+func (s *fakeServer) findProject(locator string) *fakeProject {
+	if id, ok := fakeLocator(locator, "id", "id"); ok {
+		return s.projects[id]
+	}
+	if name, ok := fakeLocator(locator, "name", "id"); ok {
+		for _, p := range s.projects {
+			if p.Name == name {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeServer) projectReference(p *fakeProject) fakeObject {
+	ref := fakeObject{
+		"id":     p.ID,
+		"name":   p.Name,
+		"href":   "/app/rest/projects/id:" + p.ID,
+		"webUrl": fakeRootURL + "/project.html?projectId=" + p.ID,
+	}
+	if p.Description != "" {
+		ref["description"] = p.Description
+	}
+	if p.ParentID != "" {
+		ref["parentProjectId"] = p.ParentID
+	}
+	return ref
+}
+
+func (s *fakeServer) serveProjects(r *fakeRequest) fakeResponse {
+	if len(r.path) == 1 {
+		if r.method == http.MethodPost {
+			return s.createProject(r)
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	p := s.findProject(r.path[1])
+	if p == nil {
+		return fakeNotFound("No project found by locator '%s'.", r.path[1])
+	}
+
+	if len(r.path) == 2 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(s.projectJSON(p))
+		case http.MethodDelete:
+			if p.ID == "_Root" {
+				return fakeBadRequest("Root project cannot be deleted")
+			}
+			s.deleteProject(p.ID)
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	switch r.path[2] {
+	case "name", "description":
+		if r.method != http.MethodPut {
+			return fakeMethodNotAllowed(r)
+		}
+		if r.path[2] == "name" {
+			p.Name = string(r.body)
+		} else {
+			p.Description = string(r.body)
+		}
+		return fakeText(string(r.body))
+	case "parentProject":
+		if r.method != http.MethodPut {
+			return fakeMethodNotAllowed(r)
+		}
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		parent := s.findProject(fakeString(body, "id"))
+		if parent == nil {
+			return fakeNotFound("No project found by locator 'id:%s'.", fakeString(body, "id"))
+		}
+		p.ParentID = parent.ID
+		return fakeJSON(s.projectReference(parent))
+	case "parameters":
+		return serveFakeParameters(r, 3, &p.Parameters, s.projectParameters(p))
+	case "order":
+		return s.serveProjectOrder(r, p)
+	case "defaultTemplate":
+		return s.serveProjectDefaultTemplate(r, p)
+	}
+	return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+}
+
+// serveProjectDefaultTemplate serves the default template of a project, which can be inherited from a parent project
+func (s *fakeServer) serveProjectDefaultTemplate(r *fakeRequest, p *fakeProject) fakeResponse {
+	switch r.method {
+	case http.MethodGet:
+		for current := p; current != nil; current = s.projects[current.ParentID] {
+			if bt, ok := s.buildTypes[current.DefaultTemplateID]; ok {
+				ref := s.buildTypeReference(bt)
+				if current != p {
+					ref["inherited"] = true
+				}
+				return fakeJSON(ref)
+			}
+		}
+		return fakeNotFound("No default template present")
+	case http.MethodPut:
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		bt := s.buildTypes[fakeString(body, "id")]
+		if bt == nil || !bt.Template {
+			return fakeNotFound("No build type template is found by id '%s'.", fakeString(body, "id"))
+		}
+		p.DefaultTemplateID = bt.ID
+		return fakeJSON(s.buildTypeReference(bt))
+	case http.MethodDelete:
+		p.DefaultTemplateID = ""
+		return fakeNoContent()
+	}
+	return fakeMethodNotAllowed(r)
+}
+
+// serveProjectOrder serves the order of the build types and subprojects of a project: the ordered ones first, then the other ones
+func (s *fakeServer) serveProjectOrder(r *fakeRequest, p *fakeProject) fakeResponse {
+	if len(r.path) != 4 {
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+
+	var item string
+	var order *[]string
+	var children []string
+	switch r.path[3] {
+	case "buildTypes":
+		item, order = "buildType", &p.BuildTypeOrder
+		for _, id := range s.sortedBuildTypeIDs() {
+			if bt := s.buildTypes[id]; bt.ProjectID == p.ID && !bt.Template {
+				children = append(children, id)
+			}
+		}
+	case "projects":
+		item, order = "project", &p.ProjectOrder
+		for _, id := range s.sortedProjectIDs() {
+			if s.projects[id].ParentID == p.ID {
+				children = append(children, id)
+			}
+		}
+	default:
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+
+	switch r.method {
+	case http.MethodGet:
+	case http.MethodPut:
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		var ids []string
+		for _, i := range fakeItems(body, item) {
+			id := fakeString(i, "id")
+			if !sliceContains(children, id) {
+				return fakeNotFound("No %s found by locator 'id:%s' in project '%s'.", item, id, p.ID)
+			}
+			ids = append(ids, id)
+		}
+		*order = ids
+	default:
+		return fakeMethodNotAllowed(r)
+	}
+
+	var items []fakeObject
+	for _, id := range *order {
+		if sliceContains(children, id) {
+			items = append(items, fakeObject{"id": id})
+		}
+	}
+	for _, id := range children {
+		if !sliceContains(*order, id) {
+			items = append(items, fakeObject{"id": id})
+		}
+	}
+	return fakeJSON(fakeCollection(item, items))
+}
+
+func (s *fakeServer) createProject(r *fakeRequest) fakeResponse {
+	body, err := fakeDecode(r)
+	if err != nil {
+		return fakeBadRequest(err.Error())
+	}
+
+	name := fakeString(body, "name")
+	if name == "" {
+		return fakeBadRequest("Project name cannot be empty.")
+	}
+	parentID := fakeString(body, "parentProject", "id")
+	if parentID == "" {
+		parentID = fakeString(body, "parentProjectId")
+	}
+	if parentID == "" {
+		parentID = "_Root"
+	}
+	if s.projects[parentID] == nil {
+		return fakeNotFound("No project found by locator 'id:%s'.", parentID)
+	}
+	for _, p := range s.projects {
+		if p.ParentID == parentID && p.Name == name {
+			return fakeBadRequest("Project with this name already exists.")
+		}
+	}
+
+	id := fakeString(body, "id")
+	if id == "" {
+		id = fakeGenerateID(parentID, name, func(id string) bool { return s.projects[id] != nil })
+	} else if s.projects[id] != nil {
+		return fakeBadRequest("Project ID \"%s\" is already used by another project", id)
+	}
+
+	p := &fakeProject{ID: id, Name: name, ParentID: parentID}
+	s.projects[id] = p
+
+	// New projects use the agent pools of their parent
+	for _, pool := range s.agentPools {
+		if sliceContains(pool.Projects, parentID) {
+			pool.Projects = append(pool.Projects, id)
+		}
+	}
+
+	return fakeJSON(s.projectReference(p))
+}
+
+func (s *fakeServer) projectJSON(p *fakeProject) fakeObject {
+	out := s.projectReference(p)
+	out["parameters"] = fakeProperties(s.projectParameters(p))
+	if parent, ok := s.projects[p.ParentID]; ok {
+		out["parentProject"] = s.projectReference(parent)
+	}
+
+	var buildTypes, templates, projects []fakeObject
+	for _, id := range s.sortedBuildTypeIDs() {
+		bt := s.buildTypes[id]
+		if bt.ProjectID != p.ID {
+			continue
+		}
+		if bt.Template {
+			templates = append(templates, s.buildTypeReference(bt))
+		} else {
+			buildTypes = append(buildTypes, s.buildTypeReference(bt))
+		}
+	}
+	for _, id := range s.sortedProjectIDs() {
+		if child := s.projects[id]; child.ParentID == p.ID {
+			projects = append(projects, s.projectReference(child))
+		}
+	}
+	out["buildTypes"] = fakeCollection("buildType", buildTypes)
+	out["templates"] = fakeCollection("buildType", templates)
+	out["projects"] = fakeCollection("project", projects)
+	return out
+}
+
+func (s *fakeServer) deleteProject(id string) {
+	for _, child := range s.projects {
+		if child.ParentID == id {
+			s.deleteProject(child.ID)
+		}
+	}
+	for _, bt := range s.buildTypes {
+		if bt.ProjectID == id {
+			delete(s.buildTypes, bt.ID)
+		}
+	}
+	for _, vcs := range s.vcsRoots {
+		if vcs.ProjectID == id {
+			delete(s.vcsRoots, vcs.ID)
+		}
+	}
+	for _, pool := range s.agentPools {
+		pool.Projects = sliceRemove(pool.Projects, id)
+	}
+	delete(s.projects, id)
+}
+
+func (s *fakeServer) sortedProjectIDs() []string {
+	ids := make([]string, 0, len(s.projects))
+	for id := range s.projects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *fakeServer) sortedBuildTypeIDs() []string {
+	ids := make([]string, 0, len(s.buildTypes))
+	for id := range s.buildTypes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *fakeServer) findBuildType(locator string) *fakeBuildType {
+	if id, ok := fakeLocator(locator, "id", "id"); ok {
+		return s.buildTypes[id]
+	}
+	return nil
+}
+
+func (s *fakeServer) buildTypeReference(bt *fakeBuildType) fakeObject {
+	ref := fakeObject{
+		"id":          bt.ID,
+		"name":        bt.Name,
+		"projectId":   bt.ProjectID,
+		"projectName": s.projects[bt.ProjectID].Name,
+		"href":        "/app/rest/buildTypes/id:" + bt.ID,
+		"webUrl":      fakeRootURL + "/viewType.html?buildTypeId=" + bt.ID,
+	}
+	if bt.Description != "" {
+		ref["description"] = bt.Description
+	}
+	if bt.Template {
+		ref["templateFlag"] = true
+	}
+	if bt.Paused {
+		ref["paused"] = true
+	}
+	return ref
+}
+
+func (s *fakeServer) buildTypeJSON(bt *fakeBuildType) fakeObject {
+	out := s.buildTypeReference(bt)
+	out["templateFlag"] = bt.Template
+	out["project"] = s.projectReference(s.projects[bt.ProjectID])
+	out["settings"] = s.buildTypeSettings(bt)
+	out["parameters"] = fakeProperties(s.buildTypeParameters(bt))
+	for name, c := range fakeBuildTypeCollections {
+		out[name] = fakeCollection(c.item, bt.Children[name])
+	}
+	out["steps"] = fakeCollection("step", s.buildTypeSteps(bt))
+	return out
+}
+
+// buildTypeSteps returns the steps of the templates of the build type, flagged as inherited, followed by its own steps
+func (s *fakeServer) buildTypeSteps(bt *fakeBuildType) []fakeObject {
+	var out []fakeObject
+	for _, t := range bt.Children["templates"] {
+		template := s.buildTypes[fakeString(t, "id")]
+		if template == nil {
+			continue
+		}
+		for _, step := range template.Children["steps"] {
+			inherited := fakeObject{"inherited": true}
+			for k, v := range step {
+				inherited[k] = v
+			}
+			out = append(out, inherited)
+		}
+	}
+	return append(out, bt.Children["steps"]...)
+}
+
+// buildTypeSettings returns the settings of the build type as TeamCity does: empty values are omitted,
+// and build configurations, unlike templates, always have a build counter.
+func (s *fakeServer) buildTypeSettings(bt *fakeBuildType) fakeObject {
+	var settings []fakeObject
+	counter := false
+	for _, p := range bt.Settings {
+		name := fakeString(p, "name")
+		if fakeString(p, "value") == "" || (name == "buildNumberCounter" && bt.Template) {
+			continue
+		}
+		counter = counter || name == "buildNumberCounter"
+		settings = append(settings, p)
+	}
+	if !counter && !bt.Template {
+		settings = append(settings, fakeObject{"name": "buildNumberCounter", "value": "1"})
+	}
+	return fakeProperties(settings)
+}
+
+func (s *fakeServer) serveBuildTypes(r *fakeRequest) fakeResponse {
+	if len(r.path) == 1 {
+		if r.method == http.MethodPost {
+			return s.createBuildType(r)
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	bt := s.findBuildType(r.path[1])
+	if bt == nil {
+		return fakeNotFound("No build type nor template is found by id '%s'.", strings.TrimPrefix(r.path[1], "id:"))
+	}
+
+	if len(r.path) == 2 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(s.buildTypeJSON(bt))
+		case http.MethodDelete:
+			delete(s.buildTypes, bt.ID)
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	switch r.path[2] {
+	case "name", "description":
+		if r.method != http.MethodPut {
+			return fakeMethodNotAllowed(r)
+		}
+		if r.path[2] == "name" {
+			bt.Name = string(r.body)
+		} else {
+			bt.Description = string(r.body)
+		}
+		return fakeText(string(r.body))
+	case "paused":
+		switch r.method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if bt.Template {
+				return fakeBadRequest("Template cannot be paused")
+			}
+			bt.Paused = string(r.body) == "true"
+		default:
+			return fakeMethodNotAllowed(r)
+		}
+		return fakeText(strconv.FormatBool(bt.Paused))
+	case "settings":
+		return s.serveBuildTypeSettings(r, bt)
+	case "parameters":
+		return serveFakeParameters(r, 3, &bt.Parameters, s.buildTypeParameters(bt))
+	}
+	if _, ok := fakeBuildTypeCollections[r.path[2]]; ok {
+		return s.serveBuildTypeCollection(r, bt, r.path[2])
+	}
+	return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+}
+
+func (s *fakeServer) serveBuildTypeSettings(r *fakeRequest, bt *fakeBuildType) fakeResponse {
+	if len(r.path) == 3 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(s.buildTypeSettings(bt))
+		case http.MethodPut:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			bt.Settings = fakeItems(body, "property")
+			return fakeJSON(s.buildTypeSettings(bt))
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	name := r.path[3]
+	switch r.method {
+	case http.MethodGet:
+		for _, p := range bt.Settings {
+			if fakeString(p, "name") == name {
+				return fakeText(fakeString(p, "value"))
+			}
+		}
+		return fakeText("")
+	case http.MethodPut:
+		bt.Settings = fakeSetProperty(bt.Settings, name, string(r.body))
+		return fakeText(string(r.body))
+	}
+	return fakeMethodNotAllowed(r)
+}
+
+func (s *fakeServer) createBuildType(r *fakeRequest) fakeResponse {
+	body, err := fakeDecode(r)
+	if err != nil {
+		return fakeBadRequest(err.Error())
+	}
+
+	name := fakeString(body, "name")
+	if name == "" {
+		return fakeBadRequest("Build configuration name cannot be empty.")
+	}
+	projectID := fakeString(body, "projectId")
+	if projectID == "" {
+		projectID = fakeString(body, "project", "id")
+	}
+	if s.projects[projectID] == nil {
+		return fakeNotFound("No project found by locator 'id:%s'.", projectID)
+	}
+	for _, bt := range s.buildTypes {
+		if bt.ProjectID == projectID && bt.Name == name {
+			return fakeBadRequest("Build configuration or template with name \"%s\" already exists in project", name)
+		}
+	}
+
+	id := fakeString(body, "id")
+	if id == "" {
+		id = fakeGenerateID(projectID, name, func(id string) bool { return s.buildTypes[id] != nil })
+	} else if s.buildTypes[id] != nil {
+		return fakeBadRequest("Build configuration ID \"%s\" is already used", id)
+	}
+
+	template, _ := body["templateFlag"].(bool)
+	bt := &fakeBuildType{
+		ID:          id,
+		ProjectID:   projectID,
+		Name:        name,
+		Description: fakeString(body, "description"),
+		Template:    template,
+		Children:    make(map[string][]fakeObject),
+		lastIDs:     make(map[string]int),
+	}
+	if settings, ok := body["settings"].(fakeObject); ok {
+		bt.Settings = fakeItems(settings, "property")
+	}
+	if params, ok := body["parameters"].(fakeObject); ok {
+		bt.Parameters = fakeItems(params, "property")
+	}
+	if steps, ok := body["steps"].(fakeObject); ok {
+		for _, step := range fakeItems(steps, "step") {
+			s.addBuildTypeItem(bt, "steps", step)
+		}
+	}
+	if templates, ok := body["templates"].(fakeObject); ok {
+		for _, t := range fakeItems(templates, "buildType") {
+			if resp := s.addBuildTypeItem(bt, "templates", t); resp.status != http.StatusOK {
+				return resp
+			}
+		}
+	}
+	s.buildTypes[id] = bt
+
+	return fakeJSON(s.buildTypeReference(bt))
+}
+
+func (s *fakeServer) serveBuildTypeCollection(r *fakeRequest, bt *fakeBuildType, name string) fakeResponse {
+	c := fakeBuildTypeCollections[name]
+	if len(r.path) == 3 {
+		switch r.method {
+		case http.MethodGet:
+			if name == "steps" {
+				return fakeJSON(fakeCollection(c.item, s.buildTypeSteps(bt)))
+			}
+			return fakeJSON(fakeCollection(c.item, bt.Children[name]))
+		case http.MethodPost:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			return s.addBuildTypeItem(bt, name, body)
+		case http.MethodPut:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			bt.Children[name] = nil
+			for _, item := range fakeItems(body, c.item) {
+				if name == "steps" {
+					// Inherited steps come from the templates, and the own steps keep their ids
+					if inherited, _ := item["inherited"].(bool); inherited {
+						continue
+					}
+				}
+				if resp := s.addBuildTypeItem(bt, name, item); resp.status != http.StatusOK {
+					return resp
+				}
+			}
+			return fakeJSON(fakeCollection(c.item, bt.Children[name]))
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	id := r.path[3]
+	idx := -1
+	for i, item := range bt.Children[name] {
+		if fakeString(item, "id") == id {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return fakeNotFound("No %s with id '%s' is found in the build configuration.", c.item, id)
+	}
+
+	if len(r.path) == 4 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(bt.Children[name][idx])
+		case http.MethodPut:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			body["id"] = id
+			if name == "vcs-root-entries" {
+				// The entry of a VCS root keeps referencing it, only its checkout rules can change
+				body["vcs-root"] = bt.Children[name][idx]["vcs-root"]
+			}
+			bt.Children[name][idx] = fakeNormalize(body)
+			return fakeJSON(body)
+		case http.MethodDelete:
+			bt.Children[name] = append(bt.Children[name][:idx], bt.Children[name][idx+1:]...)
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+	return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+}
+
+// addBuildTypeItem adds an item to a collection of the build type, resolving what it references
+func (s *fakeServer) addBuildTypeItem(bt *fakeBuildType, name string, item fakeObject) fakeResponse {
+	c := fakeBuildTypeCollections[name]
+	item = fakeNormalize(item)
+
+	switch name {
+	case "vcs-root-entries":
+		vcsID := fakeString(item, "vcs-root", "id")
+		vcs := s.vcsRoots[vcsID]
+		if vcs == nil {
+			return fakeNotFound("No VCS root found by locator 'id:%s'.", vcsID)
+		}
+		item["id"] = vcs.ID
+		item["vcs-root"] = s.vcsRootReference(vcs)
+	case "templates":
+		template := s.buildTypes[fakeString(item, "id")]
+		if template == nil || !template.Template {
+			return fakeNotFound("No build type template is found by id '%s'.", fakeString(item, "id"))
+		}
+		item = s.buildTypeReference(template)
+	case "snapshot-dependencies", "artifact-dependencies":
+		sourceID := fakeString(item, "source-buildType", "id")
+		source := s.buildTypes[sourceID]
+		if source == nil {
+			return fakeNotFound("No build type nor template is found by id '%s'.", sourceID)
+		}
+		item["source-buildType"] = s.buildTypeReference(source)
+		if name == "snapshot-dependencies" {
+			item["id"] = source.ID
+		}
+	}
+
+	if c.idPrefix != "" && fakeString(item, "id") == "" {
+		var last int
+		if name == "steps" {
+			s.lastStepID++
+			last = s.lastStepID
+		} else {
+			bt.lastIDs[name]++
+			last = bt.lastIDs[name]
+		}
+		item["id"] = fmt.Sprintf("%s%d", c.idPrefix, last)
+	}
+	for _, existing := range bt.Children[name] {
+		if fakeString(existing, "id") == fakeString(item, "id") {
+			return fakeBadRequest("%s with id '%s' already exists in the build configuration", c.item, fakeString(item, "id"))
+		}
+	}
+
+	bt.Children[name] = append(bt.Children[name], item)
+	return fakeJSON(item)
+}
+
+func (s *fakeServer) vcsRootReference(vcs *fakeVcsRoot) fakeObject {
+	return fakeObject{
+		"id":        vcs.ID,
+		"name":      vcs.Name,
+		"href":      "/app/rest/vcs-roots/id:" + vcs.ID,
+		"projectId": vcs.ProjectID,
+		"project":   fakeObject{"id": vcs.ProjectID},
+	}
+}
+
+func (s *fakeServer) serveVcsRoots(r *fakeRequest) fakeResponse {
+	if len(r.path) == 1 {
+		if r.method == http.MethodPost {
+			return s.createVcsRoot(r)
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	id, _ := fakeLocator(r.path[1], "id", "id")
+	vcs := s.vcsRoots[id]
+	if vcs == nil {
+		return fakeNotFound("No VCS root found by locator '%s'.", r.path[1])
+	}
+
+	if len(r.path) == 2 {
+		switch r.method {
+		case http.MethodGet:
+			out := s.vcsRootReference(vcs)
+			out["vcsName"] = vcs.VcsName
+			out["project"] = s.projectReference(s.projects[vcs.ProjectID])
+			// Secure properties, like passwords, are never returned
+			var props []fakeObject
+			for _, p := range vcs.Properties {
+				if !strings.HasPrefix(fakeString(p, "name"), "secure:") {
+					props = append(props, p)
+				}
+			}
+			out["properties"] = fakeProperties(props)
+			if vcs.ModificationCheckInterval > 0 {
+				out["modificationCheckInterval"] = vcs.ModificationCheckInterval
+			}
+			return fakeJSON(out)
+		case http.MethodDelete:
+			for _, bt := range s.buildTypes {
+				if resp := s.serveBuildTypeCollection(&fakeRequest{method: http.MethodDelete, path: []string{"buildTypes", bt.ID, "vcs-root-entries", vcs.ID}}, bt, "vcs-root-entries"); resp.status != http.StatusNoContent && resp.status != http.StatusNotFound {
+					return resp
+				}
+			}
+			delete(s.vcsRoots, vcs.ID)
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	if r.method != http.MethodPut {
+		return fakeMethodNotAllowed(r)
+	}
+	switch r.path[2] {
+	case "properties":
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		vcs.Properties = fakeItems(body, "property")
+		return fakeJSON(fakeProperties(vcs.Properties))
+	case "name":
+		vcs.Name = string(r.body)
+	case "projectId":
+		if s.projects[string(r.body)] == nil {
+			return fakeNotFound("No project found by locator 'id:%s'.", r.body)
+		}
+		vcs.ProjectID = string(r.body)
+	case "modificationCheckInterval":
+		interval, err := strconv.Atoi(string(r.body))
+		if err != nil {
+			return fakeBadRequest("invalid modificationCheckInterval '%s'", r.body)
+		}
+		vcs.ModificationCheckInterval = interval
+	default:
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+	return fakeText(string(r.body))
+}
+
+func (s *fakeServer) createVcsRoot(r *fakeRequest) fakeResponse {
+	body, err := fakeDecode(r)
+	if err != nil {
+		return fakeBadRequest(err.Error())
+	}
+
+	name := fakeString(body, "name")
+	projectID := fakeString(body, "project", "id")
+	if name == "" {
+		return fakeBadRequest("VCS root name cannot be empty.")
+	}
+	if s.projects[projectID] == nil {
+		return fakeNotFound("No project found by locator 'id:%s'.", projectID)
+	}
+	for _, vcs := range s.vcsRoots {
+		if vcs.ProjectID == projectID && vcs.Name == name {
+			return fakeBadRequest("VCS root with name \"%s\" already exists in project", name)
+		}
+	}
+
+	id := fakeString(body, "id")
+	if id == "" {
+		id = fakeGenerateID(projectID, name, func(id string) bool { return s.vcsRoots[id] != nil })
+	}
+	interval, _ := body["modificationCheckInterval"].(float64)
+	vcs := &fakeVcsRoot{
+		ID:                        id,
+		Name:                      name,
+		ProjectID:                 projectID,
+		VcsName:                   fakeString(body, "vcsName"),
+		ModificationCheckInterval: int(interval),
+	}
+	if props, ok := body["properties"].(fakeObject); ok {
+		vcs.Properties = fakeItems(props, "property")
+	}
+	s.vcsRoots[id] = vcs
+
+	return fakeJSON(s.vcsRootReference(vcs))
+}
+
+func (s *fakeServer) agentPoolJSON(pool *fakeAgentPool) fakeObject {
+	out := fakeObject{
+		"id":   pool.ID,
+		"name": pool.Name,
+		"href": fmt.Sprintf("/app/rest/agentPools/id:%d", pool.ID),
+	}
+	if pool.MaxAgents != nil {
+		out["maxAgents"] = *pool.MaxAgents
+	}
+	var projects []fakeObject
+	for _, id := range pool.Projects {
+		projects = append(projects, s.projectReference(s.projects[id]))
+	}
+	out["projects"] = fakeCollection("project", projects)
+	return out
+}
+
+func (s *fakeServer) serveAgentPools(r *fakeRequest) fakeResponse {
+	if len(r.path) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			projectID := ""
+			if locator := r.query.Get("locator"); locator != "" {
+				projectID = strings.TrimSuffix(strings.TrimPrefix(locator, "project:(id:"), ")")
+			}
+			var pools []fakeObject
+			for _, id := range s.sortedAgentPoolIDs() {
+				pool := s.agentPools[id]
+				if projectID == "" || sliceContains(pool.Projects, projectID) {
+					pools = append(pools, fakeObject{"id": pool.ID, "name": pool.Name})
+				}
+			}
+			return fakeJSON(fakeCollection("agentPool", pools))
+		case http.MethodPost:
+			body, err := fakeDecode(r)
+			if err != nil {
+				return fakeBadRequest(err.Error())
+			}
+			name := fakeString(body, "name")
+			for _, pool := range s.agentPools {
+				if pool.Name == name {
+					return fakeBadRequest("Agent pool with name \"%s\" already exists", name)
+				}
+			}
+			pool := &fakeAgentPool{ID: len(s.agentPools), Name: name}
+			for s.agentPools[pool.ID] != nil {
+				pool.ID++
+			}
+			if v, ok := body["maxAgents"].(float64); ok {
+				maxAgents := int(v)
+				pool.MaxAgents = &maxAgents
+			}
+			s.agentPools[pool.ID] = pool
+			return fakeJSON(s.agentPoolJSON(pool))
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	var pool *fakeAgentPool
+	if id, ok := fakeLocator(r.path[1], "id", "id"); ok {
+		if n, err := strconv.Atoi(id); err == nil {
+			pool = s.agentPools[n]
+		}
+	} else if name, ok := fakeLocator(r.path[1], "name", "id"); ok {
+		for _, p := range s.agentPools {
+			if p.Name == name {
+				pool = p
+			}
+		}
+	}
+	if pool == nil {
+		return fakeNotFound("No agent pool is found by locator '%s'.", r.path[1])
+	}
+
+	if len(r.path) == 2 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(s.agentPoolJSON(pool))
+		case http.MethodPut:
+			// Updating agent pools isn't supported by TeamCity
+			return fakeMethodNotAllowed(r)
+		case http.MethodDelete:
+			if pool.ID == 0 {
+				return fakeBadRequest("Default agent pool cannot be deleted")
+			}
+			delete(s.agentPools, pool.ID)
+			for _, projectID := range pool.Projects {
+				s.ensureProjectHasAgentPool(projectID)
+			}
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	if r.path[2] != "projects" {
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+	if len(r.path) == 3 && r.method == http.MethodPost {
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		p := s.projects[fakeString(body, "id")]
+		if p == nil {
+			return fakeNotFound("No project found by locator 'id:%s'.", fakeString(body, "id"))
+		}
+		if !sliceContains(pool.Projects, p.ID) {
+			pool.Projects = append(pool.Projects, p.ID)
+		}
+		return fakeJSON(s.projectReference(p))
+	}
+	if len(r.path) == 4 && r.method == http.MethodDelete {
+		p := s.findProject(r.path[3])
+		if p == nil || !sliceContains(pool.Projects, p.ID) {
+			return fakeNotFound("Project '%s' is not assigned to agent pool '%s'.", r.path[3], pool.Name)
+		}
+		pool.Projects = sliceRemove(pool.Projects, p.ID)
+		s.ensureProjectHasAgentPool(p.ID)
+		return fakeNoContent()
+	}
+	return fakeMethodNotAllowed(r)
+}
+
+// ensureProjectHasAgentPool assigns the project to the default pool if it isn't assigned to any pool anymore
+func (s *fakeServer) ensureProjectHasAgentPool(projectID string) {
+	if s.projects[projectID] == nil {
+		return
+	}
+	for _, pool := range s.agentPools {
+		if sliceContains(pool.Projects, projectID) {
+			return
+		}
+	}
+	s.agentPools[0].Projects = append(s.agentPools[0].Projects, projectID)
+}
+
+func (s *fakeServer) sortedAgentPoolIDs() []int {
+	ids := make([]int, 0, len(s.agentPools))
+	for id := range s.agentPools {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *fakeServer) groupJSON(g *fakeGroup) fakeObject {
+	out := fakeObject{
+		"key":  g.Key,
+		"name": g.Name,
+		"href": "/app/rest/userGroups/key:" + g.Key,
+	}
+	if g.Description != "" {
+		out["description"] = g.Description
+	}
+	return out
+}
+
+func (s *fakeServer) serveGroups(r *fakeRequest) fakeResponse {
+	if len(r.path) == 1 {
+		if r.method != http.MethodPost {
+			return fakeMethodNotAllowed(r)
+		}
+		body, err := fakeDecode(r)
+		if err != nil {
+			return fakeBadRequest(err.Error())
+		}
+		g := &fakeGroup{
+			Key:         fakeString(body, "key"),
+			Name:        fakeString(body, "name"),
+			Description: fakeString(body, "description"),
+		}
+		if s.groups[g.Key] != nil {
+			return fakeError(http.StatusInternalServerError, "Cannot create group: group with the same key already exists")
+		}
+		for _, existing := range s.groups {
+			if existing.Name == g.Name {
+				return fakeError(http.StatusInternalServerError, "Cannot create group: group with the same name already exists")
+			}
+		}
+		s.groups[g.Key] = g
+		return fakeJSON(s.groupJSON(g))
+	}
+
+	key, _ := fakeLocator(r.path[1], "key", "key")
+	g := s.groups[key]
+	if g == nil {
+		return fakeNotFound("No group found by locator '%s'.", r.path[1])
+	}
+
+	if len(r.path) == 2 {
+		switch r.method {
+		case http.MethodGet:
+			return fakeJSON(s.groupJSON(g))
+		case http.MethodDelete:
+			delete(s.groups, g.Key)
+			return fakeNoContent()
+		}
+		return fakeMethodNotAllowed(r)
+	}
+
+	if r.path[2] != "roles" {
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+	if len(r.path) == 3 {
+		if r.method == http.MethodGet {
+			return fakeJSON(fakeObject{"role": g.Roles})
+		}
+		return fakeMethodNotAllowed(r)
+	}
+	if len(r.path) != 5 {
+		return fakeNotFound("Unknown path '%s'", strings.Join(r.path, "/"))
+	}
+
+	roleID, scope := r.path[3], r.path[4]
+	if projectID := strings.TrimPrefix(scope, "p:"); projectID != scope && s.projects[projectID] == nil {
+		return fakeNotFound("No project found by locator 'id:%s'.", projectID)
+	}
+	idx := -1
+	for i, role := range g.Roles {
+		if fakeString(role, "roleId") == roleID && fakeString(role, "scope") == scope {
+			idx = i
+		}
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		if idx < 0 {
+			return fakeNotFound("Group '%s' does not have role '%s' in scope '%s'.", g.Key, roleID, scope)
+		}
+		return fakeJSON(g.Roles[idx])
+	case http.MethodPost:
+		role := fakeObject{
+			"roleId": roleID,
+			"scope":  scope,
+			"href":   fmt.Sprintf("/app/rest/userGroups/key:%s/roles/%s/%s", g.Key, roleID, scope),
+		}
+		if idx < 0 {
+			g.Roles = append(g.Roles, role)
+		}
+		return fakeJSON(role)
+	case http.MethodDelete:
+		if idx < 0 {
+			return fakeNotFound("Group '%s' does not have role '%s' in scope '%s'.", g.Key, roleID, scope)
+		}
+		g.Roles = append(g.Roles[:idx], g.Roles[idx+1:]...)
+		return fakeNoContent()
+	}
+	return fakeMethodNotAllowed(r)
+}
+
+func sliceContains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sliceRemove(s []string, v string) []string {
+	out := make([]string, 0, len(s))
+	for _, i := range s {
+		if i != v {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,25 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceBuildConfigChildImport imports resources that belong to a build configuration, like triggers and dependencies.
+// Their ID is only unique within the build configuration, so they are imported with an ID of the form `<build_config_id>/<id>`.
+func resourceBuildConfigChildImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID %q, expected <build_config_id>/<id>", d.Id())
+	}
+
+	if err := d.Set("build_config_id", parts[0]); err != nil {
+		return nil, err
+	}
+	d.SetId(parts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
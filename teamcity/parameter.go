@@ -0,0 +1,283 @@
+package teamcity
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// parameter is a parameter as represented by the REST API, with its type specification.
+// The api client drops the specification, so typed parameters are managed through the rest client instead.
+type parameter struct {
+	Name      string         `json:"name"`
+	Value     string         `json:"value"`
+	Inherited *bool          `json:"inherited,omitempty"`
+	Type      *parameterType `json:"type,omitempty"`
+}
+
+type parameterType struct {
+	RawValue string `json:"rawValue,omitempty"`
+}
+
+type parameters struct {
+	Count int          `json:"count"`
+	Items []*parameter `json:"property"`
+}
+
+// newParameter converts a parameter modelled by the api client, which has no type specification
+func newParameter(p *api.Parameter) *parameter {
+	prop := p.Property()
+	return &parameter{
+		Name:  prop.Name,
+		Value: prop.Value,
+	}
+}
+
+// plain converts the parameter to one modelled by the api client, dropping its type specification
+func (p *parameter) plain() (*api.Parameter, error) {
+	dt, err := json.Marshal(&parameter{Name: p.Name, Value: p.Value})
+	if err != nil {
+		return nil, err
+	}
+	var out api.Parameter
+	if err := out.UnmarshalJSON(dt); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// inherited reports whether the parameter comes from a template or a parent project
+func (p *parameter) inherited() bool {
+	return p.Inherited != nil && *p.Inherited
+}
+
+// spec returns the raw type specification of the parameter, or "" if it has none
+func (p *parameter) spec() string {
+	if p.Type == nil {
+		return ""
+	}
+	return p.Type.RawValue
+}
+
+func buildConfigParametersPath(buildConfigID string) string {
+	return fmt.Sprintf("buildTypes/%s/parameters", api.LocatorID(buildConfigID))
+}
+
+func projectParametersPath(projectID string) string {
+	return fmt.Sprintf("projects/%s/parameters", api.LocatorID(projectID))
+}
+
+// getParameters returns the parameters in effect in the project or build configuration,
+// the ones it defines and the ones inherited from templates and parent projects
+func (r *restClient) getParameters(path string) ([]*parameter, error) {
+	var out parameters
+	if err := r.get(path, &out, "parameters"); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// replaceParameters sets all the parameters of the project or build configuration
+func (r *restClient) replaceParameters(path string, params []*parameter) error {
+	in := parameters{
+		Count: len(params),
+		Items: params,
+	}
+	return r.put(path, &in, nil, "parameters")
+}
+
+// Parameter types a parameter block can specify
+const (
+	parameterTypeText     = "text"
+	parameterTypePassword = "password"
+	parameterTypeCheckbox = "checkbox"
+	parameterTypeSelect   = "select"
+)
+
+var parameterTypes = []string{parameterTypeText, parameterTypePassword, parameterTypeCheckbox, parameterTypeSelect}
+
+var parameterDisplays = []string{"normal", "hidden", "prompt"}
+
+// parameterSpec is the type specification of a parameter, which drives the prompt TeamCity shows when running a build.
+// TeamCity stores it as a type followed by attributes, like `select display='prompt' data_1='a' data_2='b'`.
+type parameterSpec struct {
+	Type              string
+	Label             string
+	Description       string
+	Display           string
+	Regex             string
+	ValidationMessage string
+	CheckedValue      string
+	UncheckedValue    string
+	Options           []string
+	Multiple          bool
+	ValueSeparator    string
+}
+
+// encode returns the specification in the format TeamCity stores it
+func (s *parameterSpec) encode() string {
+	var b strings.Builder
+	b.WriteString(s.Type)
+	attr := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, " %s='%s'", name, escapeParameterSpecValue(value))
+		}
+	}
+
+	attr("label", s.Label)
+	attr("description", s.Description)
+	attr("display", s.Display)
+	switch s.Type {
+	case parameterTypeText:
+		if s.Regex != "" {
+			attr("validationMode", "regex")
+			attr("regexp", s.Regex)
+			attr("validationMessage", s.ValidationMessage)
+		}
+	case parameterTypeCheckbox:
+		attr("checkedValue", s.CheckedValue)
+		attr("uncheckedValue", s.UncheckedValue)
+	case parameterTypeSelect:
+		for i, o := range s.Options {
+			attr(fmt.Sprintf("data_%d", i+1), o)
+		}
+		if s.Multiple {
+			attr("multiple", "true")
+			attr("valueSeparator", s.ValueSeparator)
+		}
+	}
+	return b.String()
+}
+
+// isPlain reports whether the specification is the one TeamCity gives parameters without any
+func (s *parameterSpec) isPlain() bool {
+	return s.Type == parameterTypeText && s.Label == "" && s.Description == "" &&
+		(s.Display == "" || s.Display == "normal") && s.Regex == ""
+}
+
+// isPassword reports whether the specification is the one of a password without label or description
+func (s *parameterSpec) isPassword() bool {
+	return s.Type == parameterTypePassword && s.Label == "" && s.Description == ""
+}
+
+func decodeParameterSpec(v string) (*parameterSpec, error) {
+	v = strings.TrimSpace(v)
+	i := strings.IndexByte(v, ' ')
+	if i < 0 {
+		i = len(v)
+	}
+	out := &parameterSpec{Type: v[:i]}
+
+	attrs, err := decodeParameterSpecAttributes(v[i:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameter specification '%s': %s", v, err)
+	}
+	type option struct {
+		index int
+		value string
+	}
+	var options []option
+	for name, value := range attrs {
+		switch name {
+		case "label":
+			out.Label = value
+		case "description":
+			out.Description = value
+		case "display":
+			out.Display = value
+		case "regexp":
+			out.Regex = value
+		case "validationMessage":
+			out.ValidationMessage = value
+		case "checkedValue":
+			out.CheckedValue = value
+		case "uncheckedValue":
+			out.UncheckedValue = value
+		case "multiple":
+			out.Multiple = value == "true"
+		case "valueSeparator":
+			out.ValueSeparator = value
+		default:
+			if strings.HasPrefix(name, "data_") {
+				if n, err := strconv.Atoi(strings.TrimPrefix(name, "data_")); err == nil {
+					options = append(options, option{n, value})
+				}
+			}
+		}
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].index < options[j].index })
+	for _, o := range options {
+		out.Options = append(out.Options, o.value)
+	}
+	if attrs["validationMode"] != "regex" {
+		out.Regex = ""
+		out.ValidationMessage = ""
+	}
+	return out, nil
+}
+
+// decodeParameterSpecAttributes reads the name='value' attributes of a specification
+func decodeParameterSpecAttributes(v string) (map[string]string, error) {
+	out := make(map[string]string)
+	for {
+		v = strings.TrimLeft(v, " ")
+		if v == "" {
+			return out, nil
+		}
+		i := strings.Index(v, "='")
+		if i < 0 {
+			return nil, fmt.Errorf("attribute without value at '%s'", v)
+		}
+		name := v[:i]
+		v = v[i+2:]
+
+		var value strings.Builder
+		closed := false
+		for len(v) > 0 && !closed {
+			c := v[0]
+			v = v[1:]
+			switch {
+			case c == '\'':
+				closed = true
+			case c == '|' && len(v) > 0:
+				value.WriteString(unescapeParameterSpecChar(v[0]))
+				v = v[1:]
+			default:
+				value.WriteByte(c)
+			}
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated value of attribute '%s'", name)
+		}
+		out[name] = value.String()
+	}
+}
+
+// TeamCity escapes specification values with '|', like service messages
+var parameterSpecEscaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+)
+
+func escapeParameterSpecValue(v string) string {
+	return parameterSpecEscaper.Replace(v)
+}
+
+func unescapeParameterSpecChar(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	default:
+		return string(c)
+	}
+}
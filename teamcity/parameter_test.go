@@ -0,0 +1,145 @@
+package teamcity
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestParameterSpec_RoundTrip(t *testing.T) {
+	cases := []struct {
+		spec    parameterSpec
+		encoded string
+	}{
+		{
+			spec:    parameterSpec{Type: "password", Display: "hidden"},
+			encoded: "password display='hidden'",
+		},
+		{
+			spec:    parameterSpec{Type: "checkbox", Label: "Deploy", Display: "prompt", CheckedValue: "yes", UncheckedValue: "no"},
+			encoded: "checkbox label='Deploy' display='prompt' checkedValue='yes' uncheckedValue='no'",
+		},
+		{
+			spec:    parameterSpec{Type: "select", Display: "normal", Options: []string{"dev", "staging", "prod"}, Multiple: true, ValueSeparator: ","},
+			encoded: "select display='normal' data_1='dev' data_2='staging' data_3='prod' multiple='true' valueSeparator=','",
+		},
+		{
+			spec:    parameterSpec{Type: "text", Description: "It's [required]", Display: "normal", Regex: `^\d+|latest$`, ValidationMessage: "A number or 'latest'"},
+			encoded: `text description='It|'s |[required|]' display='normal' validationMode='regex' regexp='^\d+||latest$' validationMessage='A number or |'latest|''`,
+		},
+	}
+
+	for _, c := range cases {
+		encoded := c.spec.encode()
+		if encoded != c.encoded {
+			t.Errorf("encode = %s, expected %s", encoded, c.encoded)
+		}
+		decoded, err := decodeParameterSpec(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*decoded, c.spec) {
+			t.Errorf("decodeParameterSpec(%s) = %#v, expected %#v", encoded, *decoded, c.spec)
+		}
+	}
+}
+
+func TestParameterSpec_IsPlain(t *testing.T) {
+	// TeamCity gives this specification to parameters edited in the UI without specifying one
+	spec, err := decodeParameterSpec("text display='normal' validationMode='any'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !spec.isPlain() {
+		t.Errorf("expected specification %#v to be plain", spec)
+	}
+}
+
+func TestDecodeParameterSpec_Unterminated(t *testing.T) {
+	if _, err := decodeParameterSpec("text label='unterminated"); err == nil {
+		t.Errorf("expected an error for an unterminated attribute value")
+	}
+}
+
+func TestSuppressPasswordParamDiff(t *testing.T) {
+	hash := hashPasswordParam("s3cr3t")
+	if !suppressPasswordParamDiff("password_params.env.TOKEN", hash, "s3cr3t", nil) {
+		t.Errorf("expected the diff of an unchanged password to be suppressed")
+	}
+	if suppressPasswordParamDiff("password_params.env.TOKEN", hash, "changed", nil) {
+		t.Errorf("expected the diff of a changed password not to be suppressed")
+	}
+	// Imported passwords have no hash
+	if suppressPasswordParamDiff("password_params.env.TOKEN", "", "s3cr3t", nil) {
+		t.Errorf("expected the diff of a password without hash not to be suppressed")
+	}
+	if suppressPasswordParamDiff("password_params.%", "1", "2", nil) {
+		t.Errorf("expected the diff of the password count not to be suppressed")
+	}
+}
+
+func TestFlattenParameterSpecs_HashesPasswords(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"parameter": parameterSchema()}, map[string]interface{}{
+		"parameter": []interface{}{
+			map[string]interface{}{"name": "region", "value": "eu", "type": "select", "options": []interface{}{"eu", "us"}},
+			map[string]interface{}{"name": "env.TOKEN", "value": "s3cr3t", "type": "password"},
+		},
+	})
+	password := &parameterSpec{Type: parameterTypePassword, Label: "Token"}
+	region := &parameterSpec{Type: parameterTypeSelect, Options: []string{"eu", "us"}}
+	params := []*parameter{{Name: "env.TOKEN"}, {Name: "region", Value: "eu"}}
+
+	out := flattenParameterSpecs(d, params, map[string]*parameterSpec{"env.TOKEN": password, "region": region})
+	if len(out) != 2 || out[0]["name"] != "region" || out[1]["name"] != "env.TOKEN" {
+		t.Fatalf("expected the parameters in the order of the configuration, got %v", out)
+	}
+	if v := out[1]["value"]; v != hashPasswordParam("s3cr3t") {
+		t.Errorf("expected the hash of the password, got %q", v)
+	}
+}
+
+func TestEffectiveParamsDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "Project_Build",
+		Attributes: map[string]string{
+			"id":                          "Project_Build",
+			"name":                        "build",
+			"project_id":                  "Project",
+			"env_params.%":                "1",
+			"env_params.DEPLOY":           "staging",
+			"password_params.%":           "1",
+			"password_params.env.TOKEN":   hashPasswordParam("secret"),
+			"effective_params.%":          "2",
+			"effective_params.env.DEPLOY": "staging",
+			"effective_params.inherited":  "from project",
+		},
+	}
+	config := func(deploy string) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":            "build",
+			"project_id":      "Project",
+			"env_params":      map[string]interface{}{"DEPLOY": deploy},
+			"password_params": map[string]interface{}{"env.TOKEN": "secret"},
+		})
+	}
+
+	diff, err := resourceBuildConfig().Diff(context.Background(), state, config("production"), &Meta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Attributes["effective_params.%"]; d == nil || !d.NewComputed {
+		t.Errorf("expected effective_params to be computed when a parameter changes, got %v", d)
+	}
+
+	diff, err = resourceBuildConfig().Diff(context.Background(), state, config("staging"), &Meta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && diff.Attributes["effective_params.%"] != nil {
+		// The hash of the unchanged password in the state isn't a change either
+		t.Errorf("expected effective_params to be kept when no parameter changes, got %v", diff.Attributes["effective_params.%"])
+	}
+}
@@ -0,0 +1,72 @@
+package teamcity
+
+import (
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The order TeamCity shows the build configurations and subprojects of a project in.
+// The api client doesn't support it, so it's managed through the rest client.
+
+type projectOrderItem struct {
+	ID string `json:"id"`
+}
+
+type projectOrder struct {
+	BuildTypes []*projectOrderItem `json:"buildType,omitempty"`
+	Projects   []*projectOrderItem `json:"project,omitempty"`
+}
+
+func (o *projectOrder) ids() []string {
+	items := append(o.BuildTypes, o.Projects...)
+	out := make([]string, 0, len(items))
+	for _, i := range items {
+		out = append(out, i.ID)
+	}
+	return out
+}
+
+func projectBuildConfigOrderPath(projectID string) string {
+	return fmt.Sprintf("projects/%s/order/buildTypes", api.LocatorID(projectID))
+}
+
+func projectSubprojectOrderPath(projectID string) string {
+	return fmt.Sprintf("projects/%s/order/projects", api.LocatorID(projectID))
+}
+
+// getBuildConfigOrder returns the IDs of the build configurations of the project, in the order TeamCity shows them
+func (r *restClient) getBuildConfigOrder(projectID string) ([]string, error) {
+	var out projectOrder
+	if err := r.get(projectBuildConfigOrderPath(projectID), &out, "build configuration order"); err != nil {
+		return nil, err
+	}
+	return out.ids(), nil
+}
+
+// setBuildConfigOrder shows the given build configurations first, the other ones after them
+func (r *restClient) setBuildConfigOrder(projectID string, ids []string) error {
+	in := projectOrder{BuildTypes: make([]*projectOrderItem, 0, len(ids))}
+	for _, id := range ids {
+		in.BuildTypes = append(in.BuildTypes, &projectOrderItem{ID: id})
+	}
+	return r.put(projectBuildConfigOrderPath(projectID), &in, nil, "build configuration order")
+}
+
+// getSubprojectOrder returns the IDs of the subprojects of the project, in the order TeamCity shows them
+func (r *restClient) getSubprojectOrder(projectID string) ([]string, error) {
+	var out projectOrder
+	if err := r.get(projectSubprojectOrderPath(projectID), &out, "subproject order"); err != nil {
+		return nil, err
+	}
+	return out.ids(), nil
+}
+
+// setSubprojectOrder shows the given subprojects first, the other ones after them
+func (r *restClient) setSubprojectOrder(projectID string, ids []string) error {
+	in := projectOrder{Projects: make([]*projectOrderItem, 0, len(ids))}
+	for _, id := range ids {
+		in.Projects = append(in.Projects, &projectOrderItem{ID: id})
+	}
+	return r.put(projectSubprojectOrderPath(projectID), &in, nil, "subproject order")
+}
@@ -0,0 +1,48 @@
+package teamcity
+
+import (
+	"fmt"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// The default template of a project is attached to the build configurations created in it.
+// The api client doesn't support it, so it's managed through the rest client.
+
+type projectDefaultTemplate struct {
+	ID        string `json:"id"`
+	Inherited *bool  `json:"inherited,omitempty"`
+}
+
+func projectDefaultTemplatePath(projectID string) string {
+	return fmt.Sprintf("projects/%s/defaultTemplate", api.LocatorID(projectID))
+}
+
+// getProjectDefaultTemplate returns the ID of the default template set on the project, or "" if it has none.
+// A default template inherited from a parent project is not returned.
+func (r *restClient) getProjectDefaultTemplate(projectID string) (string, error) {
+	var out projectDefaultTemplate
+	if err := r.get(projectDefaultTemplatePath(projectID), &out, "project default template"); err != nil {
+		// TeamCity answers not found when there's no default template
+		if isNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if out.Inherited != nil && *out.Inherited {
+		return "", nil
+	}
+	return out.ID, nil
+}
+
+// setProjectDefaultTemplate sets the default template of the project, or removes it if templateID is ""
+func (r *restClient) setProjectDefaultTemplate(projectID string, templateID string) error {
+	if templateID == "" {
+		err := r.delete(projectDefaultTemplatePath(projectID), "project default template")
+		if err != nil && isNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	return r.put(projectDefaultTemplatePath(projectID), &projectDefaultTemplate{ID: templateID}, nil, "project default template")
+}
@@ -1,14 +1,16 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 //Provider is the plugin entry point
-func Provider() terraform.ResourceProvider {
+func Provider() *schema.Provider {
 	return &schema.Provider{
 		ResourcesMap: map[string]*schema.Resource{
 			"teamcity_agent_pool":                      resourceAgentPool(),
@@ -20,9 +22,12 @@ func Provider() terraform.ResourceProvider {
 			"teamcity_build_trigger_schedule":          resourceBuildTriggerSchedule(),
 			"teamcity_build_trigger_vcs":               resourceBuildTriggerVcs(),
 			"teamcity_feature_commit_status_publisher": resourceFeatureCommitStatusPublisher(),
+			"teamcity_failure_condition_metric":        resourceFailureConditionMetric(),
 			"teamcity_group":                           resourceGroup(),
 			"teamcity_group_role_assignment":           resourceGroupRoleAssignment(),
 			"teamcity_project":                         resourceProject(),
+			"teamcity_project_default_template":        resourceProjectDefaultTemplate(),
+			"teamcity_project_order":                   resourceProjectOrder(),
 			"teamcity_snapshot_dependency":             resourceSnapshotDependency(),
 			"teamcity_vcs_root_git":                    resourceVcsRootGit(),
 		},
@@ -54,17 +59,98 @@ func Provider() terraform.ResourceProvider {
 				ConflictsWith: []string{"token"},
 				DefaultFunc:   schema.EnvDefaultFunc("TEAMCITY_PASSWORD", nil),
 			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TEAMCITY_MAX_RETRIES", 4),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "How many times a request is retried when the server is temporarily unavailable.",
+			},
+			"retry_wait_min": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TEAMCITY_RETRY_WAIT_MIN", 1),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Seconds to wait before the first retry. Following waits grow exponentially up to retry_wait_max.",
+			},
+			"retry_wait_max": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TEAMCITY_RETRY_WAIT_MAX", 30),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum seconds to wait between two retries.",
+			},
+			"max_requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TEAMCITY_MAX_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum number of requests per second sent to the server. Use 0 for no limit.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TEAMCITY_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests in flight at the same time. Use 0 for no limit.",
+			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_pem"},
+				DefaultFunc:   schema.EnvDefaultFunc("TEAMCITY_CA_CERT_FILE", nil),
+				Description:   "Path to a PEM-encoded CA bundle used to verify the server certificate.",
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_file"},
+				DefaultFunc:   schema.EnvDefaultFunc("TEAMCITY_CA_CERT_PEM", nil),
+				Description:   "PEM-encoded CA bundle used to verify the server certificate.",
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TEAMCITY_CLIENT_CERT", nil),
+				Description: "PEM-encoded client certificate for mutual TLS, or the path to a file containing it.",
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("TEAMCITY_CLIENT_KEY", nil),
+				Description: "PEM-encoded private key for the client certificate, or the path to a file containing it.",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TEAMCITY_INSECURE_SKIP_VERIFY", false),
+				Description: "Disables verification of the server certificate. Only use this for testing.",
+			},
 		},
 
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	config := Config{
 		Address:  d.Get("address").(string),
 		Username: d.Get("username").(string),
 		Password: d.Get("password").(string),
+
+		MaxRetries:   d.Get("max_retries").(int),
+		RetryWaitMin: time.Duration(d.Get("retry_wait_min").(int)) * time.Second,
+		RetryWaitMax: time.Duration(d.Get("retry_wait_max").(int)) * time.Second,
+
+		MaxRequestsPerSecond:  d.Get("max_requests_per_second").(float64),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+
+		CACertFile:         d.Get("ca_cert_file").(string),
+		CACertPEM:          d.Get("ca_cert_pem").(string),
+		ClientCert:         d.Get("client_cert").(string),
+		ClientKey:          d.Get("client_key").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
 	}
 
 	if v, ok := d.GetOk("token"); ok && v.(string) != "" {
@@ -75,8 +161,13 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	}
 
 	if config.Token == "" && config.Username == "" {
-		return nil, fmt.Errorf("Error configuring provider: either a `token` or `username` must be specified")
+		return nil, diag.Errorf("Error configuring provider: either a `token` or `username` must be specified")
+	}
+
+	meta, err := config.Meta(ctx)
+	if err != nil {
+		return nil, diag.Errorf("Error configuring provider: %s", err)
 	}
 
-	return config.Client()
+	return meta, nil
 }
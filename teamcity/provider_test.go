@@ -1,27 +1,34 @@
 package teamcity_test
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
-var testAccProviders map[string]terraform.ResourceProvider
+var testAccProviders map[string]*schema.Provider
 var testAccProvider *schema.Provider
 
 func init() {
-	testAccProvider = teamcity.Provider().(*schema.Provider)
-	testAccProviders = map[string]terraform.ResourceProvider{
+	testAccProvider = teamcity.Provider()
+	testAccProviders = map[string]*schema.Provider{
 		"teamcity": testAccProvider,
 	}
 }
 
 func TestProvider(t *testing.T) {
-	if err := teamcity.Provider().(*schema.Provider).InternalValidate(); err != nil {
+	if err := teamcity.Provider().InternalValidate(); err != nil {
 		t.Fatalf("Error: %s", err)
 	}
 }
@@ -30,6 +37,87 @@ func TestProvider_impl(t *testing.T) {
 	var _ = teamcity.Provider()
 }
 
+func TestProvider_CancelsRequestsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/rest/server" {
+			w.Write([]byte(`{"version": "2019.2.2 (build 72059)", "versionMajor": 2019, "versionMinor": 2}`))
+			return
+		}
+		// Hang until the client gives up
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	p := teamcity.Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"address":     srv.URL,
+		"token":       "token",
+		"max_retries": 0,
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	// Build configurations are read through the rest client, projects through the api client
+	for _, name := range []string{"teamcity_project", "teamcity_build_config"} {
+		r := p.ResourcesMap[name]
+		d := r.TestResourceData()
+		d.SetId("Project")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		start := time.Now()
+		diags = r.ReadContext(ctx, d, p.Meta())
+		cancel()
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, "`timeouts` block") {
+			t.Fatalf("expected a timeout error reading %s with a cancelled context, got: %v", name, diags)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected the request reading %s to be cancelled along with the context, took %s", name, elapsed)
+		}
+	}
+}
+
+func TestProvider_KeepsDefaultClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "2019.2.2 (build 72059)", "versionMajor": 2019, "versionMinor": 2}`))
+	}))
+	defer srv.Close()
+
+	transport := http.DefaultClient.Transport
+	diags := teamcity.Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"address":              srv.URL,
+		"token":                "token",
+		"insecure_skip_verify": true,
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+	if http.DefaultClient.Transport != transport {
+		t.Fatalf("expected configuring the provider to leave http.DefaultClient unchanged")
+	}
+}
+
+func TestProvider_ResourcesDeclareTimeouts(t *testing.T) {
+	p := teamcity.Provider()
+	for name, r := range p.ResourcesMap {
+		if r.Timeouts == nil || r.Timeouts.Create == nil || r.Timeouts.Read == nil || r.Timeouts.Delete == nil {
+			t.Errorf("%s: expected create, read and delete timeouts", name)
+			continue
+		}
+		if (r.UpdateContext != nil) != (r.Timeouts.Update != nil) {
+			t.Errorf("%s: expected an update timeout only if the resource can be updated", name)
+		}
+	}
+	for name, r := range p.DataSourcesMap {
+		if r.Timeouts == nil || r.Timeouts.Read == nil {
+			t.Errorf("%s: expected a read timeout", name)
+		}
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv("TEAMCITY_ADDR"); v == "" {
 		t.Fatal("TEAMCITY_ADDR must be set for acceptance tests")
@@ -42,3 +130,14 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("Either `TEAMCITY_TOKEN` or `TEAMCITY_USER` and `TEAMCITY_PASSWORD` must be set for acceptance tests")
 	}
 }
+
+// testAccBuildConfigChildImportStateIdFunc returns the `<build_config_id>/<id>` import ID of a resource nested in a build configuration
+func testAccBuildConfigChildImportStateIdFunc(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("Not found: %s", resourceName)
+		}
+		return fmt.Sprintf("%s/%s", rs.Primary.Attributes["build_config_id"], rs.Primary.ID), nil
+	}
+}
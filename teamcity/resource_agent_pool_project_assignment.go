@@ -1,23 +1,25 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 
-	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAgentPoolProjectAssignment() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAgentPoolProjectAssignmentCreate,
-		Read:   resourceAgentPoolProjectAssignmentRead,
-		Delete: resourceAgentPoolProjectAssignmentDelete,
+		CreateContext: resourceAgentPoolProjectAssignmentCreate,
+		ReadContext:   resourceAgentPoolProjectAssignmentRead,
+		DeleteContext: resourceAgentPoolProjectAssignmentDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"agent_pool_id": {
@@ -42,14 +44,14 @@ func resourceAgentPoolProjectAssignment() *schema.Resource {
 	}
 }
 
-func resourceAgentPoolProjectAssignmentCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceAgentPoolProjectAssignmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	agentPoolId := d.Get("agent_pool_id").(int)
 	projectId := d.Get("project_id").(string)
 
 	if err := client.AgentPools.AssignProject(agentPoolId, projectId); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(fmt.Sprintf("%d|%s", agentPoolId, projectId))
@@ -58,7 +60,7 @@ func resourceAgentPoolProjectAssignmentCreate(d *schema.ResourceData, meta inter
 	if disassociateFromOtherPools {
 		agentPoolsForProject, err := client.AgentPools.ListForProject(projectId)
 		if err != nil {
-			return fmt.Errorf("error listing agent pools for project %q: %s", projectId, err)
+			return diag.Errorf("error listing agent pools for project %q: %s", projectId, err)
 		}
 
 		for _, pool := range agentPoolsForProject.AgentPools {
@@ -68,31 +70,31 @@ func resourceAgentPoolProjectAssignmentCreate(d *schema.ResourceData, meta inter
 
 			log.Printf("[DEBUG] Removing association between Project %q and Agent Pool %d", projectId, pool.Id)
 			if err := client.AgentPools.UnassignProject(pool.Id, projectId); err != nil {
-				return fmt.Errorf("Error removing association between Project %q and Agent Pool %d: %+v", projectId, pool.Id, err)
+				return diag.Errorf("Error removing association between Project %q and Agent Pool %d: %+v", projectId, pool.Id, err)
 			}
 		}
 	}
 
-	return resourceAgentPoolProjectAssignmentRead(d, client)
+	return resourceAgentPoolProjectAssignmentRead(ctx, d, meta)
 }
 
-func resourceAgentPoolProjectAssignmentRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceAgentPoolProjectAssignmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	id, err := ParseAgentPoolProjectAssignmentID(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	agentPool, err := client.AgentPools.GetByID(id.AgentPoolId)
 	if err != nil {
-		if strings.Contains(err.Error(), "404") {
+		if isNotFoundError(err) {
 			log.Printf("[DEBUG] Agent Pool not found, so assignment can't - removing from state!")
 			d.SetId("")
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	projectExists := false
@@ -117,12 +119,12 @@ func resourceAgentPoolProjectAssignmentRead(d *schema.ResourceData, meta interfa
 	return nil
 }
 
-func resourceAgentPoolProjectAssignmentDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceAgentPoolProjectAssignmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	id, err := ParseAgentPoolProjectAssignmentID(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	// TeamCity requires that a Project is in at least one Agent Pool
@@ -130,7 +132,7 @@ func resourceAgentPoolProjectAssignmentDelete(d *schema.ResourceData, meta inter
 	// since that's guaranteed to be around
 	agentPoolsForProject, err := client.AgentPools.ListForProject(id.ProjectId)
 	if err != nil {
-		return fmt.Errorf("error retrieving agent pools for project: %s", err)
+		return diag.Errorf("error retrieving agent pools for project: %s", err)
 	}
 
 	needsReassigningToRootProject := false
@@ -143,12 +145,12 @@ func resourceAgentPoolProjectAssignmentDelete(d *schema.ResourceData, meta inter
 		log.Printf("[DEBUG] Since this has no other assignments, adding one to the Default Agent Pool")
 		defaultAgentPoolId := 0
 		if err := client.AgentPools.AssignProject(defaultAgentPoolId, id.ProjectId); err != nil {
-			return fmt.Errorf("error assigning project to Default Agent Pool: %s", err)
+			return diag.Errorf("error assigning project to Default Agent Pool: %s", err)
 		}
 	}
 
 	if err := client.AgentPools.UnassignProject(id.AgentPoolId, id.ProjectId); err != nil {
-		return fmt.Errorf("error unassigning project %q from pool %d: %s", id.ProjectId, id.AgentPoolId, err)
+		return diag.Errorf("error unassigning project %q from pool %d: %s", id.ProjectId, id.AgentPoolId, err)
 	}
 
 	return nil
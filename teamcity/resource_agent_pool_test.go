@@ -7,9 +7,9 @@ import (
 	"testing"
 	"time"
 
-	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamCityAgentPool_Basic(t *testing.T) {
@@ -66,7 +66,7 @@ func TestAccTeamCityAgentPool_Complete(t *testing.T) {
 
 func testAccCheckTeamCityAgentPoolExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		rs, ok := s.RootModule().Resources[n]
 		if !ok {
 			return fmt.Errorf("Not found: %s", n)
@@ -87,7 +87,7 @@ func testAccCheckTeamCityAgentPoolExists(n string) resource.TestCheckFunc {
 }
 
 func testAccCheckTeamCityAgentPoolDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	for _, r := range s.RootModule().Resources {
 		if r.Type != "teamcity_agent_pool" {
 			continue
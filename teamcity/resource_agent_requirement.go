@@ -1,22 +1,25 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAgentRequirement() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAgentRequirementCreate,
-		Read:   resourceAgentRequirementRead,
-		Delete: resourceAgentRequirementDelete,
+		CreateContext: resourceAgentRequirementCreate,
+		ReadContext:   resourceAgentRequirementRead,
+		DeleteContext: resourceAgentRequirementDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -44,20 +47,20 @@ func resourceAgentRequirement() *schema.Resource {
 	}
 }
 
-func resourceAgentRequirementCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceAgentRequirementCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var buildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
-	srv := client.AgentRequirementService(buildConfigID)
-
 	var condition, name, value string
 
 	if v, ok := d.GetOk("condition"); ok {
@@ -74,60 +77,57 @@ func resourceAgentRequirementCreate(d *schema.ResourceData, meta interface{}) er
 
 	dt, err := api.NewAgentRequirement(condition, name, value)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	out, err := srv.Create(dt)
+	out, err := meta.(*Meta).rest(ctx).addAgentRequirement(buildConfigID, dt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID)
 
-	return resourceAgentRequirementRead(d, meta)
+	return resourceAgentRequirementRead(ctx, d, meta)
 }
 
-func resourceAgentRequirementRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).AgentRequirementService(d.Get("build_config_id").(string))
-
-	dt, err := getAgentRequirement(client, d.Id())
+func resourceAgentRequirementRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	dt, err := meta.(*Meta).rest(ctx).getAgentRequirement(d.Get("build_config_id").(string), d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Agent requirement '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("condition", dt.Condition); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("name", dt.Name()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if v := dt.Value(); v != "" {
 		if err := d.Set("value", v); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	return nil
 }
 
-func resourceAgentRequirementDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	svr := client.AgentRequirementService(d.Get("build_config_id").(string))
-
-	return svr.Delete(d.Id())
-}
-
-func getAgentRequirement(c *api.AgentRequirementService, id string) (*api.AgentRequirement, error) {
-	dt, err := c.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
+func resourceAgentRequirementDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	svr := client.AgentRequirementService(buildConfigID)
 
-	return dt, nil
+	return diag.FromErr(svr.Delete(d.Id()))
 }
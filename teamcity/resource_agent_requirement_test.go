@@ -6,8 +6,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcityAgentRequirement_Basic(t *testing.T) {
@@ -31,6 +32,12 @@ func TestAccTeamcityAgentRequirement_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resName, "value", "somevalue"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
 		},
 	})
 }
@@ -73,7 +80,7 @@ func TestAccTeamcityAgentRequirement_Update(t *testing.T) {
 
 func testAccCheckTeamcityAgentRequirementDestroy(bt *string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return AgentRequirementDestroyHelper(s, bt, client)
 	}
 }
@@ -101,7 +108,7 @@ func AgentRequirementDestroyHelper(s *terraform.State, bt *string, client *api.C
 
 func testAccCheckTeamcityAgentRequirementExists(n string, bt *string, snap *api.AgentRequirement) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return teamcityAgentRequirementExistsHelper(n, bt, s, client, snap)
 	}
 }
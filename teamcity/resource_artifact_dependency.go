@@ -1,21 +1,24 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceArtifactDependency() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceArtifactDependencyCreate,
-		Read:   resourceArtifactDependencyRead,
-		Delete: resourceArtifactDependencyDelete,
+		CreateContext: resourceArtifactDependencyCreate,
+		ReadContext:   resourceArtifactDependencyRead,
+		DeleteContext: resourceArtifactDependencyDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -63,77 +66,84 @@ func resourceArtifactDependency() *schema.Resource {
 	}
 }
 
-func resourceArtifactDependencyCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceArtifactDependencyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var buildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
-	depService := client.DependencyService(buildConfigID)
-	opt, err := expandArtifactDependencyOptions(d)
-	if err != nil {
-		return err
+	opt, diags := expandArtifactDependencyOptions(d)
+	if diags.HasError() {
+		return diags
 	}
 	dep, err := api.NewArtifactDependency(d.Get("source_build_config_id").(string), opt)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	out, err := depService.AddArtifactDependency(dep)
+	out, err := meta.(*Meta).rest(ctx).addArtifactDependency(buildConfigID, dep)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID())
 
-	return resourceArtifactDependencyRead(d, meta)
+	return resourceArtifactDependencyRead(ctx, d, meta)
 }
 
-func resourceArtifactDependencyRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).DependencyService(d.Get("build_config_id").(string))
+func resourceArtifactDependencyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx).DependencyService(d.Get("build_config_id").(string))
 
 	dt, err := getArtifactDependency(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Artifact dependency '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	if dt.Options.CleanDestination {
-		if err := d.Set("clean_destination", dt.Options.CleanDestination); err != nil {
-			return err
-		}
+	if err := d.Set("clean_destination", dt.Options.CleanDestination); err != nil {
+		return diag.FromErr(err)
 	}
 	if err := d.Set("dependency_revision", string(dt.Options.ArtifactRevisionType)); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("path_rules", flattenStringSlice(dt.Options.PathRules)); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if dt.Options.ArtifactRevisionType == api.BuildWithSpecifiedNumber || dt.Options.ArtifactRevisionType == api.LastBuildFinishedWithTag {
 		if err := d.Set("revision", dt.Options.RevisionNumber); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	} else {
 		d.Set("revision", nil)
 	}
 
-	return d.Set("source_build_config_id", dt.SourceBuildTypeID)
+	return diag.FromErr(d.Set("source_build_config_id", dt.SourceBuildTypeID))
 }
 
-func resourceArtifactDependencyDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	dep := client.DependencyService(d.Get("build_config_id").(string))
+func resourceArtifactDependencyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	dep := client.DependencyService(buildConfigID)
 
-	return dep.DeleteArtifact(d.Id())
+	return diag.FromErr(dep.DeleteArtifact(d.Id()))
 }
 
 func getArtifactDependency(c *api.DependencyService, id string) (*api.ArtifactDependency, error) {
@@ -146,7 +156,7 @@ func getArtifactDependency(c *api.DependencyService, id string) (*api.ArtifactDe
 	return dt, nil
 }
 
-func expandArtifactDependencyOptions(d *schema.ResourceData) (*api.ArtifactDependencyOptions, error) {
+func expandArtifactDependencyOptions(d *schema.ResourceData) (*api.ArtifactDependencyOptions, diag.Diagnostics) {
 	var cleanDestination bool
 	var pathRules []string
 	var revision string
@@ -165,18 +175,18 @@ func expandArtifactDependencyOptions(d *schema.ResourceData) (*api.ArtifactDepen
 		if v, ok := d.GetOk("revision"); ok {
 			revision = v.(string)
 		} else {
-			return nil, fmt.Errorf("'revision' property is required if using '%s' or '%s' for 'dependency_revision'", api.LastBuildFinishedWithTag, api.BuildWithSpecifiedNumber)
+			return nil, attributeErrorf("revision", "'revision' property is required if using '%s' or '%s' for 'dependency_revision'", api.LastBuildFinishedWithTag, api.BuildWithSpecifiedNumber)
 		}
 	} else {
 		//Ignore revison and remove from config/state if not used
 		if err := d.Set("revision", nil); err != nil {
-			return nil, err
+			return nil, diag.FromErr(err)
 		}
 	}
 
 	out, err := api.NewArtifactDependencyOptions(pathRules, revisionType, cleanDestination, revision)
 	if err != nil {
-		return nil, err
+		return nil, diag.FromErr(err)
 	}
 	return out, nil
 }
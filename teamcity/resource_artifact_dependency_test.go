@@ -7,8 +7,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcityArtifactDependency_Basic(t *testing.T) {
@@ -33,6 +34,12 @@ func TestAccTeamcityArtifactDependency_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resName, "path_rules.0", "+:*"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
 		},
 	})
 }
@@ -202,7 +209,7 @@ func testAccCheckDependencySourceBuildType(n string, dep *api.ArtifactDependency
 
 func testAccCheckTeamcityArtifactDependencyDestroy(bt *string, resourceType string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return ArtifactDependencyDestroyHelper(s, bt, client, resourceType)
 	}
 }
@@ -230,7 +237,7 @@ func ArtifactDependencyDestroyHelper(s *terraform.State, bt *string, client *api
 
 func testAccCheckTeamcityArtifactDependencyExists(n string, bt *string, snap *api.ArtifactDependency) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return teamcityArtifactDependencyExistsHelper(n, bt, s, client, snap)
 	}
 }
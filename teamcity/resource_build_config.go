@@ -2,67 +2,40 @@ package teamcity
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceBuildConfig() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceBuildConfigCreate,
-		Read:   resourceBuildConfigRead,
-		Update: resourceBuildConfigUpdate,
-		Delete: resourceBuildConfigDelete,
+		CreateContext: resourceBuildConfigCreate,
+		ReadContext:   resourceBuildConfigRead,
+		UpdateContext: resourceBuildConfigUpdate,
+		DeleteContext: resourceBuildConfigDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: updatableResourceTimeouts(),
 
-		CustomizeDiff: func(diff *schema.ResourceDiff, v interface{}) error {
-			if diff.HasChange("settings") {
-				o, n := diff.GetChange("settings")
-
-				os := o.(*schema.Set)
-				ns := n.(*schema.Set)
-				if os.Len() == 0 || ns.Len() == 0 {
-					return nil
-				}
-				osi, err := expandBuildConfigOptionsRaw(os)
-				if err != nil {
-					return err
-				}
-				nsi, err := expandBuildConfigOptionsRaw(ns)
-				if err != nil {
-					return err
-				}
-
-				if buildCounterChange(osi, nsi) {
-					var setComputed bool
-
-					// If the configuration doesn't specify the build counter, set the value from READ and mark settings as computed
-					if nsi.BuildCounter == 0 {
-						log.Printf("[INFO] Build counter not defined in config. Setting it to computed: %v after reading.", osi.BuildCounter)
-						nsi.BuildCounter = osi.BuildCounter
-						setComputed = true
-					} else if osi.BuildCounter > nsi.BuildCounter {
-						log.Printf("[INFO] Build counter computed is higher, adjusting state. Old: %v, New: %v.", osi.BuildCounter, nsi.BuildCounter)
-						nsi.BuildCounter = osi.BuildCounter
-						setComputed = true
-					}
-					if setComputed {
-						computed := flattenBuildConfigOptionsRaw(nsi)
-						_ = diff.SetNew("settings", []map[string]interface{}{computed})
-					}
-				}
-			}
-			return nil
-		},
+		CustomizeDiff: customdiff.All(
+			resourceBuildConfigSettingsDiff,
+			resourceBuildConfigStepsDiff,
+			resourceBuildConfigVcsSettingsDiff,
+			effectiveParamsDiff("env_params", "config_params", "sys_params", "parameter", "password_params", "templates"),
+		),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -74,6 +47,11 @@ func resourceBuildConfig() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+			"paused": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"project_id": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -113,7 +91,7 @@ func resourceBuildConfig() *schema.Resource {
 						"type": {
 							Type:         schema.TypeString,
 							Required:     true,
-							ValidateFunc: validation.StringInSlice([]string{"powershell", "cmd_line"}, false),
+							ValidateFunc: validation.StringInSlice([]string{"powershell", "cmd_line", "gradle", "maven", "docker", "docker_compose", "dotnet", "generic"}, false),
 						},
 						"name": {
 							Type:     schema.TypeString,
@@ -132,6 +110,119 @@ func resourceBuildConfig() *schema.Resource {
 							Type:     schema.TypeString,
 							Optional: true,
 						},
+						"tasks": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"working_dir": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"jdk_home": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"use_wrapper": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"goals": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"maven_version": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"user_settings": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"docker_command": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"context_dir": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"image_tags": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"compose_files": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"dotnet_command": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"projects": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"framework": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"configuration": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"output_dir": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"verbosity": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"Quiet", "Minimal", "Normal", "Detailed", "Diagnostic"}, false),
+						},
+						"runner_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"properties": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"execute_mode": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "default",
+							ValidateFunc: validation.StringInSlice([]string{"default", "always", "on_success", "on_failure"}, false),
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"conditions": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"condition": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(api.ConditionStrings, false),
+									},
+									"name": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"value": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -147,6 +238,80 @@ func resourceBuildConfig() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"parameter":        parameterSchema(),
+			"password_params":  passwordParamsSchema(),
+			"effective_params": effectiveParamsSchema(),
+			"failure_conditions": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"execution_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"fail_on_exit_code": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"fail_on_test_failure": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"fail_on_crash": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"fail_on_error_message": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"vcs_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"checkout_mode": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validation.StringInSlice([]string{"auto", "on_server", "on_agent", "manual"}, false),
+						},
+						"checkout_dir": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"clean_build": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"show_dependencies_changes": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"exclude_default_branch_changes": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
 			"settings": {
 				Type:       schema.TypeSet,
 				Optional:   true,
@@ -218,6 +383,67 @@ func resourceBuildConfig() *schema.Resource {
 	}
 }
 
+// resourceBuildConfigSettingsDiff keeps the build counter read from TeamCity when the configuration doesn't set it
+func resourceBuildConfigSettingsDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if diff.HasChange("settings") {
+		o, n := diff.GetChange("settings")
+
+		os := o.(*schema.Set)
+		ns := n.(*schema.Set)
+		if os.Len() == 0 || ns.Len() == 0 {
+			return nil
+		}
+		osi, err := expandBuildConfigOptionsRaw(os)
+		if err != nil {
+			return err
+		}
+		nsi, err := expandBuildConfigOptionsRaw(ns)
+		if err != nil {
+			return err
+		}
+
+		if buildCounterChange(osi, nsi) {
+			var setComputed bool
+
+			// If the configuration doesn't specify the build counter, set the value from READ and mark settings as computed
+			if nsi.BuildCounter == 0 {
+				log.Printf("[INFO] Build counter not defined in config. Setting it to computed: %v after reading.", osi.BuildCounter)
+				nsi.BuildCounter = osi.BuildCounter
+				setComputed = true
+			} else if osi.BuildCounter > nsi.BuildCounter {
+				log.Printf("[INFO] Build counter computed is higher, adjusting state. Old: %v, New: %v.", osi.BuildCounter, nsi.BuildCounter)
+				nsi.BuildCounter = osi.BuildCounter
+				setComputed = true
+			}
+			if setComputed {
+				computed := flattenBuildConfigOptionsRaw(nsi)
+				_ = diff.SetNew("settings", []map[string]interface{}{computed})
+			}
+		}
+	}
+	return nil
+}
+
+// resourceBuildConfigStepsDiff fails the plan for step settings the server doesn't support
+func resourceBuildConfigStepsDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if !diff.HasChange("step") {
+		return nil
+	}
+	return validateStepConditionsSupported(diff.Get("step").([]interface{}), v.(*Meta).ServerVersion)
+}
+
+// resourceBuildConfigVcsSettingsDiff fails the plan for a checkout mode the server doesn't support
+func resourceBuildConfigVcsSettingsDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	version := v.(*Meta).ServerVersion
+	if !diff.HasChange("vcs_settings.0.checkout_mode") || version.AtLeast(2020, 1) {
+		return nil
+	}
+	if diff.Get("vcs_settings.0.checkout_mode").(string) == "auto" {
+		return fmt.Errorf("checkout_mode \"auto\" requires TeamCity 2020.1 or later, but the server runs %s", version)
+	}
+	return nil
+}
+
 func buildCounterChange(o *api.BuildTypeOptions, n *api.BuildTypeOptions) bool {
 	return o.AllowPersonalBuildTriggering == n.AllowPersonalBuildTriggering &&
 		reflect.DeepEqual(o.ArtifactRules, n.ArtifactRules) &&
@@ -229,13 +455,13 @@ func buildCounterChange(o *api.BuildTypeOptions, n *api.BuildTypeOptions) bool {
 		o.BuildCounter != n.BuildCounter
 }
 
-func resourceBuildConfigCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceBuildConfigCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var projectID, name string
 	isTemplate := false
 
 	if err := validateBuildConfig(d); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if v, ok := d.GetOk("project_id"); ok {
@@ -262,7 +488,7 @@ func resourceBuildConfigCreate(d *schema.ResourceData, meta interface{}) error {
 		bt, err = api.NewBuildType(projectID, name)
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	//BuildType templates don't support description
@@ -272,12 +498,12 @@ func resourceBuildConfigCreate(d *schema.ResourceData, meta interface{}) error {
 
 	bt.Parameters, err = expandParameterCollection(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	opt, err := expandBuildConfigOptions(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if opt != nil {
 		bt.Options = opt
@@ -287,7 +513,7 @@ func resourceBuildConfigCreate(d *schema.ResourceData, meta interface{}) error {
 	created, err := client.BuildTypes.Create(projectID, bt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	log.Printf("[DEBUG] resourceBuildConfigCreate: sucessfully created build configuration with id = '%v'. Marking new resource.", created.ID)
@@ -297,34 +523,38 @@ func resourceBuildConfigCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] resourceBuildConfigCreate: initial creation finished. Calling resourceBuildConfigUpdate to update the rest of resource.")
 
-	return resourceBuildConfigUpdate(d, meta)
+	return resourceBuildConfigUpdate(ctx, d, meta)
 }
 
-func resourceBuildConfigUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	dt, err := getBuildConfiguration(client, d.Id())
+func resourceBuildConfigUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	unlock := meta.(*Meta).lockBuildConfig(d.Id())
+	defer unlock()
+
+	dt, err := getBuildConfiguration(meta.(*Meta).rest(ctx), d.Id())
 	log.Printf("[DEBUG] resourceBuildConfigUpdate started for resouceId: %v", d.Id())
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	if d.HasChange("is_template") {
+	if d.HasChange("is_template") || d.HasChange("paused") {
 		err := validateBuildConfig(d)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
-	var changed bool
-	if d.HasChange("sys_params") || d.HasChange("config_params") || d.HasChange("env_params") {
-		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for params")
-		dt.Parameters, err = expandParameterCollection(d)
-		if err != nil {
-			return err
-		}
-		changed = true
+	specs, err := expandParameterSpecs(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	passwords, err := expandPasswordParams(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+
+	var changed bool
 	if v, ok := d.GetOk("description"); ok {
 		if d.HasChange("description") {
 			log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for description")
@@ -348,52 +578,56 @@ func resourceBuildConfigUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	if changed {
-		_, err := client.BuildTypes.Update(dt)
+		err := meta.(*Meta).rest(ctx).updateBuildType(dt)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
-	if v, ok := d.GetOk("vcs_root"); ok {
-		vcs := v.(*schema.Set).List()
-		for _, raw := range vcs {
-			toAttach := buildVcsRootEntry(raw)
-
-			err := client.BuildTypes.AttachVcsRootEntry(dt.ID, toAttach)
-			if err != nil {
-				return err
-			}
-			log.Printf("[DEBUG] resourceBuildConfigUpdate: attached vcsRoot '%v' to build configuration", toAttach.ID)
+	if d.HasChange("paused") {
+		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for paused")
+		if err := meta.(*Meta).rest(ctx).setBuildConfigPaused(dt.ID, d.Get("paused").(bool)); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
-	if d.HasChange("step") {
-		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for steps")
-		o, n := d.GetChange("step")
-		os := o.([]interface{})
-		ns := n.([]interface{})
-
-		remove, _ := expandBuildSteps(os)
-		add, err := expandBuildSteps(ns)
+	// The api client writes all the settings at once, resetting the failure conditions and VCS settings
+	if d.HasChange("failure_conditions") || d.HasChange("vcs_settings") || changed {
+		settings := expandFailureConditions(d.Get("failure_conditions").([]interface{}))
+		for k, v := range expandVcsSettings(d.Get("vcs_settings").([]interface{})) {
+			settings[k] = v
+		}
+		log.Printf("[DEBUG] resourceBuildConfigUpdate: updating failure conditions and VCS settings")
+		if err := meta.(*Meta).rest(ctx).setBuildConfigSettings(dt.ID, settings); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
+	// Parameters are all written at once, with their type specification
+	if d.HasChanges("env_params", "config_params", "sys_params", "parameter", "password_params") {
+		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for params")
+		params, err := expandParameterList(d, specs, passwords)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
-		if len(remove) > 0 {
-			for _, s := range remove {
-				err := client.BuildTypes.DeleteStep(dt.ID, s.GetID())
-				if err != nil {
-					return err
-				}
-			}
+		if err := meta.(*Meta).rest(ctx).replaceParameters(buildConfigParametersPath(dt.ID), params); err != nil {
+			return diag.FromErr(err)
 		}
-		if len(add) > 0 {
-			for _, s := range add {
-				_, err := client.BuildTypes.AddStep(dt.ID, s)
-				if err != nil {
-					return err
-				}
-			}
+	}
+
+	if d.HasChange("vcs_root") {
+		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for vcs_root")
+		o, n := d.GetChange("vcs_root")
+		if err := updateVcsRootEntries(meta.(*Meta).rest(ctx), dt.ID, o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("step") {
+		log.Printf("[DEBUG] resourceBuildConfigUpdate: change detected for steps")
+		o, n := d.GetChange("step")
+		if err := updateBuildSteps(meta.(*Meta).rest(ctx), dt.ID, o.([]interface{}), n.([]interface{})); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
@@ -405,97 +639,125 @@ func resourceBuildConfigUpdate(d *schema.ResourceData, meta interface{}) error {
 			_, err := buildTemplateService.Attach(a)
 			log.Printf("[DEBUG] resourceBuildConfigUpdate: attached template '%v' to build configuration", a)
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 		}
 		for _, r := range remove {
 			err := buildTemplateService.Detach(r)
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 			log.Printf("[DEBUG] resourceBuildConfigUpdate: detached template '%v' from build configuration", r)
 		}
 	}
 
 	log.Printf("[DEBUG] resourceBuildConfigUpdate: updated finished. Calling 'read' to refresh state.")
-	return resourceBuildConfigRead(d, meta)
+	return resourceBuildConfigRead(ctx, d, meta)
 }
 
-func resourceBuildConfigDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceBuildConfigDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	log.Printf("[DEBUG] resourceBuildConfigDelete: destroying build configuration '%v'.", d.Id())
-	return client.BuildTypes.Delete(d.Id())
+	return diag.FromErr(client.BuildTypes.Delete(d.Id()))
 }
 
-func resourceBuildConfigRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-
+func resourceBuildConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] resourceBuildConfigRead started for resouceId: %v", d.Id())
-	dt, err := getBuildConfiguration(client, d.Id())
+	dt, err := getBuildConfiguration(meta.(*Meta).rest(ctx), d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Build configuration '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 	log.Printf("[DEBUG] BuildConfiguration '%v' retrieved successfully", dt.Name)
 	if err := d.Set("name", dt.Name); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("is_template", dt.IsTemplate); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	//description not supported for templates.
 	if !dt.IsTemplate {
 		if err := d.Set("description", dt.Description); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if err := d.Set("project_id", dt.ProjectID); err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+	//templates can't be paused.
+	if !dt.IsTemplate {
+		paused, err := meta.(*Meta).rest(ctx).getBuildConfigPaused(d.Id())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("paused", paused); err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	err = flattenParameterCollection(d, dt.Parameters)
+	params, err := meta.(*Meta).rest(ctx).getParameters(buildConfigParametersPath(d.Id()))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+	err = flattenParameterCollection(d, params)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 	err = flattenBuildConfigOptions(d, dt.Options)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	err = flattenTemplates(d, dt.Templates)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+	settings, err := meta.(*Meta).rest(ctx).getBuildConfigSettings(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("failure_conditions", flattenFailureConditions(settings)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vcs_settings", flattenVcsSettings(settings, meta.(*Meta).ServerVersion)); err != nil {
+		return diag.FromErr(err)
 	}
 
-	vcsRoots := dt.VcsRootEntries
-
-	if vcsRoots != nil && len(vcsRoots) > 0 {
-		var vcsToSave []map[string]interface{}
-		for _, el := range vcsRoots {
-			m := make(map[string]interface{})
-			m["id"] = el.ID
+	// Always set, so VCS roots detached outside of Terraform are attached again
+	vcsToSave := make([]map[string]interface{}, 0, len(dt.VcsRootEntries))
+	for _, el := range dt.VcsRootEntries {
+		m := make(map[string]interface{})
+		m["id"] = el.ID
+		m["checkout_rules"] = []string{}
+		if el.CheckoutRules != "" {
 			m["checkout_rules"] = strings.Split(el.CheckoutRules, "\\n")
-			vcsToSave = append(vcsToSave, m)
-		}
-
-		if err := d.Set("vcs_root", vcsToSave); err != nil {
-			return err
 		}
+		vcsToSave = append(vcsToSave, m)
+	}
+	if err := d.Set("vcs_root", vcsToSave); err != nil {
+		return diag.FromErr(err)
 	}
 
-	steps, err := client.BuildTypes.GetSteps(d.Id())
+	steps, err := meta.(*Meta).rest(ctx).getBuildSteps(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	if steps != nil && len(steps) > 0 {
+	// Steps inherited from templates are managed with the templates
+	steps = nonInheritedBuildSteps(steps)
+	if len(steps) > 0 {
 		var stepsToSave []map[string]interface{}
 		for _, el := range steps {
 			l, err := flattenBuildStep(el)
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 			stepsToSave = append(stepsToSave, l)
 		}
 
 		if err := d.Set("step", stepsToSave); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -510,6 +772,9 @@ func validateBuildConfig(d *schema.ResourceData) error {
 			if _, ok := d.GetOk("description"); ok {
 				return fmt.Errorf("'description' field is not supported for Build Configuration Templates. See issue https://youtrack.jetbrains.com/issue/TW-63617 for details")
 			}
+			if d.Get("paused").(bool) {
+				return errors.New("'paused' field is not supported for Build Configuration Templates, is_template = true")
+			}
 			if _, ok := d.GetOk("settings"); ok {
 				opt, err := expandBuildConfigOptions(d)
 				if err != nil {
@@ -525,8 +790,8 @@ func validateBuildConfig(d *schema.ResourceData) error {
 	return nil
 }
 
-func getBuildConfiguration(c *api.Client, id string) (*api.BuildType, error) {
-	dt, err := c.BuildTypes.GetByID(id)
+func getBuildConfiguration(c *restClient, id string) (*api.BuildType, error) {
+	dt, err := c.getBuildType(id)
 	if err != nil {
 		return nil, err
 	}
@@ -534,16 +799,81 @@ func getBuildConfiguration(c *api.Client, id string) (*api.BuildType, error) {
 	return dt, nil
 }
 
+// Runner types of build steps the api client doesn't model
+const (
+	stepTypeGradle        = "gradle-runner"
+	stepTypeMaven         = "Maven2"
+	stepTypeDocker        = "DockerCommand"
+	stepTypeDockerCompose = "DockerCompose"
+)
+
 var stepTypeMap = map[string]string{
 	api.StepTypePowershell:  "powershell",
 	api.StepTypeCommandLine: "cmd_line",
+	stepTypeGradle:          "gradle",
+	stepTypeMaven:           "maven",
+	stepTypeDocker:          "docker",
+	stepTypeDockerCompose:   "docker_compose",
+	api.StepTypeDotnetCli:   "dotnet",
 }
 
-func flattenTemplates(d *schema.ResourceData, templates *api.Templates) error {
-	if templates == nil {
-		return nil
-	}
-	templateIds := make([]string, templates.Count)
+// stepFields lists the step attributes specific to some step types, with the types supporting them.
+// The other attributes are supported by steps of every type.
+var stepFields = []struct {
+	name  string
+	types []string
+}{
+	{"file", []string{"powershell", "cmd_line", "gradle", "maven", "docker"}},
+	{"args", []string{"powershell", "cmd_line", "gradle", "maven", "docker", "dotnet"}},
+	{"code", []string{"powershell", "cmd_line", "docker"}},
+	{"tasks", []string{"gradle"}},
+	{"working_dir", []string{"gradle", "maven", "docker", "docker_compose", "dotnet"}},
+	{"jdk_home", []string{"gradle", "maven"}},
+	{"use_wrapper", []string{"gradle"}},
+	{"goals", []string{"maven"}},
+	{"maven_version", []string{"maven"}},
+	{"user_settings", []string{"maven"}},
+	{"docker_command", []string{"docker"}},
+	{"context_dir", []string{"docker"}},
+	{"image_tags", []string{"docker"}},
+	{"compose_files", []string{"docker_compose"}},
+	{"dotnet_command", []string{"dotnet"}},
+	{"projects", []string{"dotnet"}},
+	{"framework", []string{"dotnet"}},
+	{"configuration", []string{"dotnet"}},
+	{"output_dir", []string{"dotnet"}},
+	{"verbosity", []string{"dotnet"}},
+	{"runner_type", []string{"generic"}},
+	{"properties", []string{"generic"}},
+}
+
+// stepExecuteModes maps the execute_mode of steps to the step mode in TeamCity
+var stepExecuteModes = map[string]string{
+	"default":    api.StepExecuteModeDefault,
+	"always":     api.StepExecuteAlways,
+	"on_success": api.StepExecuteModeOnlyIfBuildIsSuccessful,
+	"on_failure": api.StepExecuteModeEvenWhenFailed,
+}
+
+// Maven versions are selected among the tools installed on the server, or the default one if none is specified
+const (
+	mavenToolPrefix      = "%teamcity.tool.maven."
+	mavenToolSuffix      = "%"
+	mavenDefaultSettings = "userSettingsSelection:default"
+)
+
+// The Docker runner has dedicated settings for the build and push commands, any other docker command is run with its arguments
+const (
+	dockerCommandBuild = "build"
+	dockerCommandPush  = "push"
+	dockerCommandOther = "other"
+)
+
+func flattenTemplates(d *schema.ResourceData, templates *api.Templates) error {
+	if templates == nil {
+		return nil
+	}
+	templateIds := make([]string, templates.Count)
 	if templates.Count > 0 {
 		for i, v := range templates.Items {
 			templateIds[i] = v.ID
@@ -555,23 +885,65 @@ func flattenTemplates(d *schema.ResourceData, templates *api.Templates) error {
 	return nil
 }
 
-func flattenParameterCollection(d *schema.ResourceData, params *api.Parameters) error {
-	var configParams, sysParams, envParams = flattenParameters(params)
-
-	if len(envParams) > 0 {
-		if err := d.Set("env_params", envParams); err != nil {
-			return err
+// flattenParameterCollection sets the plain parameters into the maps by kind, and the typed ones into parameter blocks.
+// Inherited parameters are only set into effective_params, with the other parameters in effect.
+func flattenParameterCollection(d *schema.ResourceData, params []*parameter) error {
+	plain := api.NewParametersEmpty()
+	var typed []*parameter
+	specs := make(map[string]*parameterSpec)
+	blocks := make(map[string]bool)
+	for _, r := range d.Get("parameter").([]interface{}) {
+		blocks[r.(map[string]interface{})["name"].(string)] = true
+	}
+	passwords := make(map[string]interface{})
+	effective := make(map[string]interface{})
+	for _, p := range params {
+		effective[p.Name] = p.Value
+		if p.inherited() {
+			continue
 		}
-	}
-	if len(sysParams) > 0 {
-		if err := d.Set("sys_params", sysParams); err != nil {
-			return err
+		if p.spec() != "" {
+			spec, err := decodeParameterSpec(p.spec())
+			if err != nil {
+				return err
+			}
+			if spec.isPassword() && !blocks[p.Name] {
+				passwords[p.Name] = flattenPasswordParam(d, p.Name)
+				continue
+			}
+			if !spec.isPlain() {
+				typed = append(typed, p)
+				specs[p.Name] = spec
+				continue
+			}
 		}
-	}
-	if len(configParams) > 0 {
-		if err := d.Set("config_params", configParams); err != nil {
+		ap, err := p.plain()
+		if err != nil {
 			return err
 		}
+		plain.AddOrReplaceParameter(ap)
+	}
+
+	if err := d.Set("parameter", flattenParameterSpecs(d, typed, specs)); err != nil {
+		return err
+	}
+	if err := d.Set("password_params", passwords); err != nil {
+		return err
+	}
+	if err := d.Set("effective_params", effective); err != nil {
+		return err
+	}
+
+	var configParams, sysParams, envParams = flattenParameters(plain)
+
+	if err := d.Set("env_params", envParams); err != nil {
+		return err
+	}
+	if err := d.Set("sys_params", sysParams); err != nil {
+		return err
+	}
+	if err := d.Set("config_params", configParams); err != nil {
+		return err
 	}
 	return nil
 }
@@ -616,6 +988,341 @@ func expandParameterCollection(d *schema.ResourceData) (*api.Parameters, error)
 	return out, nil
 }
 
+func parameterSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"value": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Sensitive:        true,
+					DiffSuppressFunc: suppressPasswordParamDiff,
+				},
+				"type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      parameterTypeText,
+					ValidateFunc: validation.StringInSlice(parameterTypes, false),
+				},
+				"label": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"display": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "normal",
+					ValidateFunc: validation.StringInSlice(parameterDisplays, false),
+				},
+				"regex": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+				"validation_message": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"checked_value": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"unchecked_value": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"options": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"allow_multiple": {
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+				"value_separator": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// expandParameterSpecs returns the parameters of the parameter blocks, with their type specification.
+// Unchanged password values are hashes in the state, so they are read from the configuration.
+func expandParameterSpecs(d *schema.ResourceData) ([]*parameter, error) {
+	raw := d.Get("parameter").([]interface{})
+	passwords := configuredPasswordParameters(d)
+	out := make([]*parameter, 0, len(raw))
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		p, err := expandParameterSpec(m)
+		if err != nil {
+			return nil, err
+		}
+		if m["type"] == parameterTypePassword {
+			if v, ok := passwords[p.Name]; ok {
+				p.Value = v
+			}
+			if strings.HasPrefix(p.Value, passwordHashPrefix) {
+				return nil, fmt.Errorf("the value of password parameter '%s' isn't known, only its hash", p.Name)
+			}
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func expandParameterSpec(dt map[string]interface{}) (*parameter, error) {
+	spec := &parameterSpec{
+		Type:              dt["type"].(string),
+		Label:             dt["label"].(string),
+		Description:       dt["description"].(string),
+		Display:           dt["display"].(string),
+		Regex:             dt["regex"].(string),
+		ValidationMessage: dt["validation_message"].(string),
+		CheckedValue:      dt["checked_value"].(string),
+		UncheckedValue:    dt["unchecked_value"].(string),
+		Multiple:          dt["allow_multiple"].(bool),
+		ValueSeparator:    dt["value_separator"].(string),
+	}
+	for _, o := range dt["options"].([]interface{}) {
+		spec.Options = append(spec.Options, o.(string))
+	}
+
+	name := dt["name"].(string)
+	if spec.Type == parameterTypeSelect && len(spec.Options) == 0 {
+		return nil, fmt.Errorf("options is required for select parameter '%s'", name)
+	}
+	if spec.Type != parameterTypeSelect && (len(spec.Options) > 0 || spec.Multiple || spec.ValueSeparator != "") {
+		return nil, fmt.Errorf("options, allow_multiple and value_separator are only supported by select parameters, found in parameter '%s'", name)
+	}
+	if spec.Type != parameterTypeText && (spec.Regex != "" || spec.ValidationMessage != "") {
+		return nil, fmt.Errorf("regex and validation_message are only supported by text parameters, found in parameter '%s'", name)
+	}
+	if spec.Type != parameterTypeCheckbox && (spec.CheckedValue != "" || spec.UncheckedValue != "") {
+		return nil, fmt.Errorf("checked_value and unchecked_value are only supported by checkbox parameters, found in parameter '%s'", name)
+	}
+	if spec.isPlain() {
+		return nil, fmt.Errorf("parameter '%s' has no specification, use config_params, env_params or sys_params for plain parameters", name)
+	}
+
+	return &parameter{
+		Name:  name,
+		Value: dt["value"].(string),
+		Type:  &parameterType{RawValue: spec.encode()},
+	}, nil
+}
+
+// configuredPasswordParameters returns the values of the password parameter blocks in the configuration, by name
+func configuredPasswordParameters(d *schema.ResourceData) map[string]string {
+	out := make(map[string]string)
+	raw := d.GetRawConfig()
+	if !raw.IsKnown() || raw.IsNull() {
+		return out
+	}
+	blocks := raw.GetAttr("parameter")
+	if !blocks.IsKnown() || blocks.IsNull() {
+		return out
+	}
+	for _, b := range blocks.AsValueSlice() {
+		name, typ, v := b.GetAttr("name"), b.GetAttr("type"), b.GetAttr("value")
+		if !name.IsKnown() || name.IsNull() || !typ.IsKnown() || typ.IsNull() || typ.AsString() != parameterTypePassword {
+			continue
+		}
+		if v.IsKnown() && !v.IsNull() {
+			out[name.AsString()] = v.AsString()
+		}
+	}
+	return out
+}
+
+func flattenParameterSpecs(d *schema.ResourceData, params []*parameter, specs map[string]*parameterSpec) []map[string]interface{} {
+	// TeamCity never returns password values, so the hashes of the applied or stored ones are kept
+	passwords := make(map[string]string)
+	order := make(map[string]int)
+	for i, r := range d.Get("parameter").([]interface{}) {
+		m := r.(map[string]interface{})
+		order[m["name"].(string)] = i
+		if v := m["value"].(string); m["type"] == parameterTypePassword && v != "" {
+			if !strings.HasPrefix(v, passwordHashPrefix) {
+				v = hashPasswordParam(v)
+			}
+			passwords[m["name"].(string)] = v
+		}
+	}
+	// The blocks keep the order of the configuration, new ones go last
+	sort.SliceStable(params, func(i, j int) bool {
+		oi, iok := order[params[i].Name]
+		oj, jok := order[params[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		return iok && !jok
+	})
+
+	out := make([]map[string]interface{}, 0, len(params))
+	for _, p := range params {
+		spec := specs[p.Name]
+		m := map[string]interface{}{
+			"name":               p.Name,
+			"value":              p.Value,
+			"type":               spec.Type,
+			"label":              spec.Label,
+			"description":        spec.Description,
+			"display":            spec.Display,
+			"regex":              spec.Regex,
+			"validation_message": spec.ValidationMessage,
+			"checked_value":      spec.CheckedValue,
+			"unchecked_value":    spec.UncheckedValue,
+			"options":            spec.Options,
+			"allow_multiple":     spec.Multiple,
+			"value_separator":    spec.ValueSeparator,
+		}
+		if spec.Display == "" {
+			m["display"] = "normal"
+		}
+		if spec.Type == parameterTypePassword {
+			m["value"] = passwords[p.Name]
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// expandParameterList returns every parameter of the resource: the plain ones, the typed ones and the passwords
+func expandParameterList(d *schema.ResourceData, specs []*parameter, passwords []*parameter) ([]*parameter, error) {
+	plain, err := expandParameterCollection(d)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*parameter, 0, len(plain.Items)+len(specs))
+	names := make(map[string]bool)
+	for _, p := range plain.Items {
+		np := newParameter(p)
+		names[np.Name] = true
+		out = append(out, np)
+	}
+	for _, p := range specs {
+		if names[p.Name] {
+			return nil, fmt.Errorf("parameter '%s' is defined both in a parameter block and in a parameters map", p.Name)
+		}
+		names[p.Name] = true
+		out = append(out, p)
+	}
+	for _, p := range passwords {
+		if names[p.Name] {
+			return nil, fmt.Errorf("parameter '%s' is defined both in password_params and in another parameter", p.Name)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func effectiveParamsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// effectiveParamsDiff plans effective_params as unknown when the given attributes change the parameters in effect,
+// so that plans don't show the values read before the change.
+// Changes are taken from the diff, where unchanged password values are suppressed, unlike HasChange which compares their hashes.
+func effectiveParamsDiff(keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+		if diff.Id() == "" {
+			return nil
+		}
+		for _, k := range keys {
+			if len(diff.GetChangedKeysPrefix(k)) > 0 {
+				return diff.SetNewComputed("effective_params")
+			}
+		}
+		return nil
+	}
+}
+
+func passwordParamsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeMap,
+		Optional:         true,
+		Sensitive:        true,
+		Elem:             &schema.Schema{Type: schema.TypeString},
+		DiffSuppressFunc: suppressPasswordParamDiff,
+	}
+}
+
+// Password values are stored in the state as hashes, which detect changes to the configured values.
+// TeamCity never returns them, so they can't be compared with the server ones.
+const passwordHashPrefix = "sha256:"
+
+func hashPasswordParam(v string) string {
+	return fmt.Sprintf("%s%x", passwordHashPrefix, sha256.Sum256([]byte(v)))
+}
+
+func suppressPasswordParamDiff(k, old, new string, d *schema.ResourceData) bool {
+	if strings.HasSuffix(k, ".%") {
+		return false
+	}
+	return old == hashPasswordParam(new)
+}
+
+// flattenPasswordParam returns the hash of the password, from the value being applied or the one already hashed in the state
+func flattenPasswordParam(d *schema.ResourceData, name string) string {
+	v, _ := d.Get("password_params").(map[string]interface{})[name].(string)
+	if v == "" || strings.HasPrefix(v, passwordHashPrefix) {
+		return v
+	}
+	return hashPasswordParam(v)
+}
+
+// expandPasswordParams returns the password parameters with their configured values.
+// Unchanged values are hashes in the state, so they are read from the configuration.
+func expandPasswordParams(d *schema.ResourceData) ([]*parameter, error) {
+	values := make(map[string]string)
+	for k, v := range d.Get("password_params").(map[string]interface{}) {
+		values[k] = v.(string)
+	}
+	if raw := d.GetRawConfig(); raw.IsKnown() && !raw.IsNull() {
+		if m := raw.GetAttr("password_params"); m.IsKnown() && !m.IsNull() {
+			for k, v := range m.AsValueMap() {
+				if v.IsKnown() && !v.IsNull() {
+					values[k] = v.AsString()
+				}
+			}
+		}
+	}
+
+	out := make([]*parameter, 0, len(values))
+	for k, v := range values {
+		if strings.HasPrefix(v, passwordHashPrefix) {
+			return nil, fmt.Errorf("the value of password parameter '%s' isn't known, only its hash", k)
+		}
+		spec := &parameterSpec{Type: parameterTypePassword, Display: "hidden"}
+		out = append(out, &parameter{
+			Name:  k,
+			Value: v,
+			Type:  &parameterType{RawValue: spec.encode()},
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
 func flattenParameters(dt *api.Parameters) (config map[string]string, sys map[string]string, env map[string]string) {
 	env, sys, config = make(map[string]string), make(map[string]string), make(map[string]string)
 	for _, p := range dt.Items {
@@ -703,20 +1410,195 @@ func flattenBuildConfigOptionsRaw(dt *api.BuildTypeOptions) map[string]interface
 	return m
 }
 
-func flattenBuildStep(s api.Step) (map[string]interface{}, error) {
-	mapType := stepTypeMap[s.Type()]
+// Settings of the failure conditions, which TeamCity omits when they have the default value
+const (
+	settingExecutionTimeout   = "executionTimeoutMin"
+	settingFailOnExitCode     = "shouldFailBuildOnBadExitCode"
+	settingFailOnTestFailure  = "shouldFailBuildIfTestsFailed"
+	settingFailOnCrash        = "shouldFailBuildOnOOMEOrCrash"
+	settingFailOnErrorMessage = "shouldFailBuildOnAnyErrorMessage"
+)
+
+var failureConditionFlags = map[string]string{
+	"fail_on_exit_code":     settingFailOnExitCode,
+	"fail_on_test_failure":  settingFailOnTestFailure,
+	"fail_on_crash":         settingFailOnCrash,
+	"fail_on_error_message": settingFailOnErrorMessage,
+}
+
+func expandFailureConditions(raw []interface{}) map[string]string {
+	out := make(map[string]string)
+	if len(raw) == 0 || raw[0] == nil {
+		return out
+	}
+	dt := raw[0].(map[string]interface{})
+	out[settingExecutionTimeout] = strconv.Itoa(dt["execution_timeout"].(int))
+	for k, setting := range failureConditionFlags {
+		out[setting] = strconv.FormatBool(dt[k].(bool))
+	}
+	return out
+}
+
+func flattenFailureConditions(settings *api.Properties) []map[string]interface{} {
+	m := map[string]interface{}{
+		"execution_timeout":     0,
+		"fail_on_exit_code":     true,
+		"fail_on_test_failure":  true,
+		"fail_on_crash":         true,
+		"fail_on_error_message": false,
+	}
+	if v, ok := settings.GetOk(settingExecutionTimeout); ok {
+		if timeout, err := strconv.Atoi(v); err == nil {
+			m["execution_timeout"] = timeout
+		}
+	}
+	for k, setting := range failureConditionFlags {
+		if v, ok := settings.GetOk(setting); ok {
+			m[k] = v == "true"
+		}
+	}
+	return []map[string]interface{}{m}
+}
+
+// Settings of the VCS checkout, which TeamCity omits when they have the default value
+const (
+	settingCheckoutMode    = "checkoutMode"
+	settingCheckoutDir     = "checkoutDirectory"
+	settingCleanBuild      = "cleanBuild"
+	settingShowDepsChanges = "showDependenciesChanges"
+	settingExcludeDefault  = "excludeDefaultBranchChanges"
+)
+
+var checkoutModes = map[string]string{
+	"auto":      "AUTO",
+	"on_server": "ON_SERVER",
+	"on_agent":  "ON_AGENT",
+	"manual":    "MANUAL",
+}
+
+// defaultCheckoutMode returns the mode TeamCity uses when the build configuration doesn't set one,
+// which is "auto" since TeamCity 2020.1, where the mode was added
+func defaultCheckoutMode(version ServerVersion) string {
+	if version.AtLeast(2020, 1) {
+		return "auto"
+	}
+	return "on_server"
+}
+
+var vcsSettingFlags = map[string]string{
+	"clean_build":                    settingCleanBuild,
+	"show_dependencies_changes":      settingShowDepsChanges,
+	"exclude_default_branch_changes": settingExcludeDefault,
+}
+
+func expandVcsSettings(raw []interface{}) map[string]string {
+	out := make(map[string]string)
+	if len(raw) == 0 || raw[0] == nil {
+		return out
+	}
+	dt := raw[0].(map[string]interface{})
+	// The checkout mode is computed, it's only empty if neither the configuration nor TeamCity set it yet
+	if mode, ok := checkoutModes[dt["checkout_mode"].(string)]; ok {
+		out[settingCheckoutMode] = mode
+	}
+	out[settingCheckoutDir] = dt["checkout_dir"].(string)
+	for k, setting := range vcsSettingFlags {
+		out[setting] = strconv.FormatBool(dt[k].(bool))
+	}
+	return out
+}
+
+func flattenVcsSettings(settings *api.Properties, version ServerVersion) []map[string]interface{} {
+	m := map[string]interface{}{
+		"checkout_mode":                  defaultCheckoutMode(version),
+		"checkout_dir":                   "",
+		"clean_build":                    false,
+		"show_dependencies_changes":      false,
+		"exclude_default_branch_changes": false,
+	}
+	if v, ok := settings.GetOk(settingCheckoutMode); ok {
+		for k, mode := range checkoutModes {
+			if mode == v {
+				m["checkout_mode"] = k
+			}
+		}
+	}
+	if v, ok := settings.GetOk(settingCheckoutDir); ok {
+		m["checkout_dir"] = v
+	}
+	for k, setting := range vcsSettingFlags {
+		if v, ok := settings.GetOk(setting); ok {
+			m[k] = v == "true"
+		}
+	}
+	return []map[string]interface{}{m}
+}
+
+func flattenBuildStep(s *buildStep) (map[string]interface{}, error) {
+	mapType := stepTypeMap[s.Type]
 	var out map[string]interface{}
 	var err error
 	switch mapType {
 	case "powershell":
-		out, err = flattenBuildStepPowershell(s.(*api.StepPowershell)), nil
+		var ps api.StepPowershell
+		if err = s.decode(&ps); err == nil {
+			out = flattenBuildStepPowershell(&ps)
+		}
 	case "cmd_line":
-		out, err = flattenBuildStepCmdLine(s.(*api.StepCommandLine)), nil
+		var cmd api.StepCommandLine
+		if err = s.decode(&cmd); err == nil {
+			out = flattenBuildStepCmdLine(&cmd)
+		}
+	case "gradle":
+		out = flattenBuildStepGradle(s)
+	case "maven":
+		out = flattenBuildStepMaven(s)
+	case "docker":
+		out = flattenBuildStepDocker(s)
+	case "docker_compose":
+		out = flattenBuildStepDockerCompose(s)
+	case "dotnet":
+		out = flattenBuildStepDotnet(s)
 	default:
-		return nil, fmt.Errorf("build step type '%s' not supported", s.Type())
+		out = flattenBuildStepGeneric(s)
 	}
-	out["step_id"] = s.GetID()
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	if err := flattenBuildStepExecution(out, s); err != nil {
+		return nil, err
+	}
+	out["step_id"] = s.ID
+	return out, nil
+}
+
+// flattenBuildStepExecution reads when the step runs, which is configured the same way for steps of every type
+func flattenBuildStepExecution(m map[string]interface{}, s *buildStep) error {
+	m["execute_mode"] = "default"
+	mode := s.property(stepModeProperty)
+	for k, v := range stepExecuteModes {
+		if v == mode {
+			m["execute_mode"] = k
+		}
+	}
+	m["enabled"] = s.Disabled == nil || !*s.Disabled
+
+	if v := s.property(stepConditionsProperty); v != "" {
+		conditions, err := decodeStepConditions(v)
+		if err != nil {
+			return err
+		}
+		out := make([]interface{}, 0, len(conditions))
+		for _, c := range conditions {
+			out = append(out, map[string]interface{}{
+				"condition": c.Condition,
+				"name":      c.Name,
+				"value":     c.Value,
+			})
+		}
+		m["conditions"] = out
+	}
+	return nil
 }
 
 func flattenBuildStepPowershell(s *api.StepPowershell) map[string]interface{} {
@@ -757,8 +1639,279 @@ func flattenBuildStepCmdLine(s *api.StepCommandLine) map[string]interface{} {
 	return m
 }
 
-func expandBuildSteps(list interface{}) ([]api.Step, error) {
-	out := make([]api.Step, 0)
+func flattenBuildStepGradle(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	if v := s.property("ui.gradleRunner.gradle.tasks.names"); v != "" {
+		m["tasks"] = v
+	}
+	if v := s.property("ui.gradleRunner.gradle.build.file"); v != "" {
+		m["file"] = v
+	}
+	if v := s.property("ui.gradleRunner.additional.gradle.cmd.params"); v != "" {
+		m["args"] = v
+	}
+	if v := s.property("teamcity.build.workingDir"); v != "" {
+		m["working_dir"] = v
+	}
+	if v := s.property("target.jdk.home"); v != "" {
+		m["jdk_home"] = v
+	}
+	m["use_wrapper"] = s.property("ui.gradleRunner.gradle.wrapper.useWrapper") == "true"
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "gradle"
+
+	return m
+}
+
+func flattenBuildStepMaven(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	if v := s.property("goals"); v != "" {
+		m["goals"] = v
+	}
+	if v := s.property("pomLocation"); v != "" {
+		m["file"] = v
+	}
+	if v := s.property("runnerArgs"); v != "" {
+		m["args"] = v
+	}
+	if v := s.property("teamcity.build.workingDir"); v != "" {
+		m["working_dir"] = v
+	}
+	if v := s.property("target.jdk.home"); v != "" {
+		m["jdk_home"] = v
+	}
+	if v := s.property("maven.path"); v != "" {
+		if strings.HasPrefix(v, mavenToolPrefix) && strings.HasSuffix(v, mavenToolSuffix) {
+			v = strings.TrimSuffix(strings.TrimPrefix(v, mavenToolPrefix), mavenToolSuffix)
+		}
+		m["maven_version"] = v
+	}
+	if v := s.property("userSettingsSelection"); v != "" && v != mavenDefaultSettings {
+		m["user_settings"] = v
+	}
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "maven"
+
+	return m
+}
+
+func flattenBuildStepDocker(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	command := s.property("docker.command.type")
+	if command == dockerCommandOther {
+		command = s.property("docker.sub.command")
+	}
+	m["docker_command"] = command
+	if command == dockerCommandBuild {
+		if v := s.property("dockerfile.path"); v != "" {
+			m["file"] = v
+		}
+		if v := s.property("dockerfile.content"); v != "" {
+			m["code"] = v
+		}
+		if v := s.property("dockerfile.contextDir"); v != "" {
+			m["context_dir"] = v
+		}
+	}
+	if v := s.property("docker.image.namesAndTags"); v != "" {
+		var tags []string
+		for _, t := range strings.Split(v, "\n") {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+		m["image_tags"] = flattenStringSlice(tags)
+	}
+	if v := s.property("docker.command.args"); v != "" {
+		m["args"] = v
+	}
+	if v := s.property("teamcity.build.workingDir"); v != "" {
+		m["working_dir"] = v
+	}
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "docker"
+
+	return m
+}
+
+func flattenBuildStepDockerCompose(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	if v := s.property("dockerCompose.file"); v != "" {
+		m["compose_files"] = flattenStringSlice(strings.Fields(v))
+	}
+	if v := s.property("teamcity.build.workingDir"); v != "" {
+		m["working_dir"] = v
+	}
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "docker_compose"
+
+	return m
+}
+
+func flattenBuildStepDotnet(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	m["dotnet_command"] = s.property("command")
+	if v := s.property("paths"); v != "" {
+		m["projects"] = flattenStringSlice(strings.Fields(v))
+	}
+	if v := s.property("framework"); v != "" {
+		m["framework"] = v
+	}
+	if v := s.property("configuration"); v != "" {
+		m["configuration"] = v
+	}
+	if v := s.property("outputDir"); v != "" {
+		m["output_dir"] = v
+	}
+	if v := s.property("verbosity"); v != "" {
+		m["verbosity"] = v
+	}
+	if v := s.property("args"); v != "" {
+		m["args"] = v
+	}
+	if v := s.property("teamcity.build.workingDir"); v != "" {
+		m["working_dir"] = v
+	}
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "dotnet"
+
+	return m
+}
+
+// flattenBuildStepGeneric reads steps of any runner type, so configurations using runners without a dedicated step type can be managed
+func flattenBuildStepGeneric(s *buildStep) map[string]interface{} {
+	m := make(map[string]interface{})
+	props := make(map[string]interface{})
+	for _, p := range s.Properties.Items {
+		if p.Name != stepModeProperty && p.Name != stepConditionsProperty {
+			props[p.Name] = p.Value
+		}
+	}
+	m["runner_type"] = s.Type
+	m["properties"] = props
+	if s.Name != "" {
+		m["name"] = s.Name
+	}
+	m["type"] = "generic"
+
+	return m
+}
+
+// updateBuildSteps changes the steps of a build configuration from the old to the new ones.
+// Steps are matched by name, then by id, and updated in place to keep their id, which template step overrides rely on.
+// Steps inherited from templates are left alone. Steps that don't match are deleted or added, then the steps are
+// reordered if needed, by writing back the steps as TeamCity returns them in the new order.
+func updateBuildSteps(rest *restClient, buildConfigID string, oraw []interface{}, nraw []interface{}) error {
+	steps, err := expandBuildSteps(nraw)
+	if err != nil {
+		return err
+	}
+	current, err := rest.getBuildSteps(buildConfigID)
+	if err != nil {
+		return err
+	}
+	// The state may hold steps inherited from templates, read before they were told apart: they're left alone
+	inherited := make(map[string]bool)
+	for _, s := range current {
+		if s.Inherited != nil && *s.Inherited {
+			inherited[s.ID] = true
+		}
+	}
+	old := make([]*buildStep, 0, len(oraw))
+	for _, raw := range oraw {
+		s, err := expandBuildStep(raw)
+		if err != nil {
+			// The id is enough to delete the step, which is replaced
+			s = &buildStep{ID: raw.(map[string]interface{})["step_id"].(string)}
+		}
+		if inherited[s.ID] {
+			continue
+		}
+		old = append(old, s)
+	}
+
+	previous := make([]*buildStep, len(steps))
+	matched := make(map[*buildStep]bool)
+	match := func(same func(o *buildStep, s *buildStep) bool) {
+		for i, s := range steps {
+			if previous[i] != nil {
+				continue
+			}
+			for _, o := range old {
+				// Steps can't change type in place
+				if !matched[o] && o.Type == s.Type && same(o, s) {
+					previous[i] = o
+					matched[o] = true
+					break
+				}
+			}
+		}
+	}
+	match(func(o *buildStep, s *buildStep) bool { return s.Name != "" && o.Name == s.Name })
+	match(func(o *buildStep, s *buildStep) bool { return s.ID != "" && o.ID == s.ID })
+
+	for _, o := range old {
+		if !matched[o] && o.ID != "" {
+			if err := rest.deleteBuildStep(buildConfigID, o.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for i, s := range steps {
+		o := previous[i]
+		if o == nil {
+			s.ID = ""
+			created, err := rest.addBuildStep(buildConfigID, s)
+			if err != nil {
+				return err
+			}
+			s.ID = created.ID
+			continue
+		}
+
+		s.ID = o.ID
+		if !reflect.DeepEqual(o, s) {
+			if _, err := rest.updateBuildStep(buildConfigID, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	current, err = rest.getBuildSteps(buildConfigID)
+	if err != nil {
+		return err
+	}
+	if !sameBuildStepOrder(nonInheritedBuildSteps(current), steps) {
+		log.Printf("[DEBUG] updateBuildSteps: reordering steps of build configuration '%s'", buildConfigID)
+		return rest.replaceBuildSteps(buildConfigID, reorderBuildSteps(current, steps))
+	}
+	return nil
+}
+
+func sameBuildStepOrder(a []*buildStep, b []*buildStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+func expandBuildSteps(list interface{}) ([]*buildStep, error) {
+	out := make([]*buildStep, 0)
 	in := list.([]interface{})
 	for _, i := range in {
 		s, err := expandBuildStep(i)
@@ -770,18 +1923,283 @@ func expandBuildSteps(list interface{}) ([]api.Step, error) {
 	return out, nil
 }
 
-func expandBuildStep(raw interface{}) (api.Step, error) {
+func expandBuildStep(raw interface{}) (*buildStep, error) {
 	localStep := raw.(map[string]interface{})
 
+	if err := validateStepFields(localStep); err != nil {
+		return nil, err
+	}
+
+	var s *buildStep
+	var err error
 	t := localStep["type"].(string)
 	switch t {
 	case "powershell":
-		return expandStepPowershell(localStep)
+		var ps *api.StepPowershell
+		if ps, err = expandStepPowershell(localStep); err == nil {
+			s, err = newBuildStep(ps)
+		}
 	case "cmd_line":
-		return expandStepCmdLine(localStep)
+		var cmd *api.StepCommandLine
+		if cmd, err = expandStepCmdLine(localStep); err == nil {
+			s, err = newBuildStep(cmd)
+		}
+	case "gradle":
+		s = expandStepGradle(localStep)
+	case "maven":
+		s = expandStepMaven(localStep)
+	case "docker":
+		s, err = expandStepDocker(localStep)
+	case "docker_compose":
+		s, err = expandStepDockerCompose(localStep)
+	case "dotnet":
+		s, err = expandStepDotnet(localStep)
+	case "generic":
+		s, err = expandStepGeneric(localStep)
 	default:
 		return nil, fmt.Errorf("unsupported step type '%s'", t)
 	}
+	if err != nil {
+		return nil, err
+	}
+	if err := expandBuildStepExecution(s, localStep); err != nil {
+		return nil, err
+	}
+
+	if v, ok := localStep["step_id"]; ok {
+		s.ID = v.(string)
+	}
+	return s, nil
+}
+
+// validateStepFields returns an error for the attributes set in the step that its type doesn't support
+func validateStepFields(dt map[string]interface{}) error {
+	t := dt["type"].(string)
+	for _, f := range stepFields {
+		if isZeroStepField(dt[f.name]) {
+			continue
+		}
+		supported := false
+		for _, st := range f.types {
+			supported = supported || st == t
+		}
+		if !supported {
+			return fmt.Errorf("%s is only supported by %s steps, found in %s", f.name, joinStepTypes(f.types), describeStep(dt))
+		}
+	}
+	return nil
+}
+
+func isZeroStepField(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// joinStepTypes returns the step types the way the documentation lists them, e.g. "gradle, maven and docker"
+func joinStepTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return strings.Join(types[:len(types)-1], ", ") + " and " + types[len(types)-1]
+}
+
+// describeStep returns the step in error messages, by name if it has one
+func describeStep(dt map[string]interface{}) string {
+	if name, _ := dt["name"].(string); name != "" {
+		return fmt.Sprintf("%s step '%s'", dt["type"], name)
+	}
+	return fmt.Sprintf("a %s step", dt["type"])
+}
+
+// expandBuildStepExecution sets when the step runs, which is configured the same way for steps of every type
+func expandBuildStepExecution(s *buildStep, dt map[string]interface{}) error {
+	mode, ok := stepExecuteModes[dt["execute_mode"].(string)]
+	if !ok {
+		mode = api.StepExecuteModeDefault
+	}
+	s.Properties.AddOrReplaceValue(stepModeProperty, mode)
+
+	if !dt["enabled"].(bool) {
+		disabled := true
+		s.Disabled = &disabled
+	}
+
+	raw := dt["conditions"].([]interface{})
+	if len(raw) == 0 {
+		return nil
+	}
+	conditions := make([]stepCondition, 0, len(raw))
+	for _, r := range raw {
+		c := r.(map[string]interface{})
+		sc := stepCondition{
+			Condition: c["condition"].(string),
+			Name:      c["name"].(string),
+			Value:     c["value"].(string),
+		}
+		if sc.Condition != api.Conditions.Exists && sc.Value == "" {
+			return fmt.Errorf("value is required for step condition '%s' on parameter '%s'", sc.Condition, sc.Name)
+		}
+		conditions = append(conditions, sc)
+	}
+	v, err := encodeStepConditions(conditions)
+	if err != nil {
+		return err
+	}
+	s.Properties.AddOrReplaceValue(stepConditionsProperty, v)
+	return nil
+}
+
+// validateStepConditionsSupported returns an error if a step has conditions and the server predates them.
+// TeamCity ignores the conditions property before 2020.1, so the steps would run unconditionally.
+func validateStepConditionsSupported(steps []interface{}, version ServerVersion) error {
+	if version.AtLeast(2020, 1) {
+		return nil
+	}
+	for _, raw := range steps {
+		dt, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if conditions, ok := dt["conditions"].([]interface{}); ok && len(conditions) > 0 {
+			return fmt.Errorf("%s has conditions, which require TeamCity 2020.1 or later, but the server runs %s", describeStep(dt), version)
+		}
+	}
+	return nil
+}
+
+func expandStepGradle(dt map[string]interface{}) *buildStep {
+	props := map[string]string{
+		"ui.gradleRunner.gradle.tasks.names":           dt["tasks"].(string),
+		"ui.gradleRunner.gradle.build.file":            dt["file"].(string),
+		"ui.gradleRunner.additional.gradle.cmd.params": dt["args"].(string),
+		"teamcity.build.workingDir":                    dt["working_dir"].(string),
+		"target.jdk.home":                              dt["jdk_home"].(string),
+	}
+	if dt["use_wrapper"].(bool) {
+		props["ui.gradleRunner.gradle.wrapper.useWrapper"] = "true"
+	}
+
+	return newRunnerBuildStep(stepTypeGradle, dt["name"].(string), props)
+}
+
+func expandStepMaven(dt map[string]interface{}) *buildStep {
+	props := map[string]string{
+		"goals":                     dt["goals"].(string),
+		"pomLocation":               dt["file"].(string),
+		"runnerArgs":                dt["args"].(string),
+		"teamcity.build.workingDir": dt["working_dir"].(string),
+		"target.jdk.home":           dt["jdk_home"].(string),
+		"userSettingsSelection":     mavenDefaultSettings,
+	}
+	if v := dt["maven_version"].(string); v != "" {
+		props["maven.path"] = mavenToolPrefix + v + mavenToolSuffix
+	}
+	if v := dt["user_settings"].(string); v != "" {
+		props["userSettingsSelection"] = v
+	}
+
+	return newRunnerBuildStep(stepTypeMaven, dt["name"].(string), props)
+}
+
+func expandStepDocker(dt map[string]interface{}) (*buildStep, error) {
+	command := dt["docker_command"].(string)
+	if command == "" {
+		return nil, errors.New("docker_command is required for docker steps")
+	}
+
+	props := map[string]string{
+		"docker.command.type":       command,
+		"docker.image.namesAndTags": strings.Join(expandStringSlice(dt["image_tags"].([]interface{})), "\n"),
+		"docker.command.args":       dt["args"].(string),
+		"teamcity.build.workingDir": dt["working_dir"].(string),
+	}
+	switch command {
+	case dockerCommandBuild:
+		file, code := dt["file"].(string), dt["code"].(string)
+		if (file == "") == (code == "") {
+			return nil, errors.New("docker build steps require either file or code, to read the Dockerfile from a path or inline content")
+		}
+		if file != "" {
+			props["dockerfile.source"] = "PATH"
+			props["dockerfile.path"] = file
+		} else {
+			props["dockerfile.source"] = "CONTENT"
+			props["dockerfile.content"] = code
+		}
+		props["dockerfile.contextDir"] = dt["context_dir"].(string)
+	case dockerCommandPush:
+	default:
+		props["docker.command.type"] = dockerCommandOther
+		props["docker.sub.command"] = command
+	}
+	if command != dockerCommandBuild && (dt["file"].(string) != "" || dt["code"].(string) != "" || dt["context_dir"].(string) != "") {
+		return nil, fmt.Errorf("file, code and context_dir are only supported by docker steps running build, found in %s", describeStep(dt))
+	}
+
+	return newRunnerBuildStep(stepTypeDocker, dt["name"].(string), props), nil
+}
+
+func expandStepDockerCompose(dt map[string]interface{}) (*buildStep, error) {
+	files := expandStringSlice(dt["compose_files"].([]interface{}))
+	if len(files) == 0 {
+		return nil, errors.New("compose_files is required for docker_compose steps")
+	}
+
+	props := map[string]string{
+		"dockerCompose.file":        strings.Join(files, " "),
+		"teamcity.build.workingDir": dt["working_dir"].(string),
+	}
+
+	return newRunnerBuildStep(stepTypeDockerCompose, dt["name"].(string), props), nil
+}
+
+func expandStepDotnet(dt map[string]interface{}) (*buildStep, error) {
+	command := dt["dotnet_command"].(string)
+	if command == "" {
+		return nil, errors.New("dotnet_command is required for dotnet steps")
+	}
+
+	props := map[string]string{
+		"command":                   command,
+		"paths":                     strings.Join(expandStringSlice(dt["projects"].([]interface{})), " "),
+		"framework":                 dt["framework"].(string),
+		"configuration":             dt["configuration"].(string),
+		"outputDir":                 dt["output_dir"].(string),
+		"verbosity":                 dt["verbosity"].(string),
+		"args":                      dt["args"].(string),
+		"teamcity.build.workingDir": dt["working_dir"].(string),
+	}
+
+	return newRunnerBuildStep(api.StepTypeDotnetCli, dt["name"].(string), props), nil
+}
+
+func expandStepGeneric(dt map[string]interface{}) (*buildStep, error) {
+	runnerType := dt["runner_type"].(string)
+	if runnerType == "" {
+		return nil, errors.New("runner_type is required for generic steps")
+	}
+	// The step would be read back with its dedicated type, and never match the configuration
+	if t, ok := stepTypeMap[runnerType]; ok {
+		return nil, fmt.Errorf("runner type '%s' is supported by steps of type '%s', which must be used instead of a generic step", runnerType, t)
+	}
+
+	props := make(map[string]string)
+	for k, v := range dt["properties"].(map[string]interface{}) {
+		props[k] = v.(string)
+	}
+
+	return newRunnerBuildStep(runnerType, dt["name"].(string), props), nil
 }
 
 func expandStepCmdLine(dt map[string]interface{}) (*api.StepCommandLine, error) {
@@ -865,6 +2283,47 @@ func buildVcsRootEntry(raw interface{}) *api.VcsRootEntry {
 	return api.NewVcsRootEntryWithRules(&api.VcsRootReference{ID: localVcs["id"].(string)}, toAttachRules)
 }
 
+// updateVcsRootEntries changes the VCS roots attached to a build configuration from the old to the new entries.
+// New VCS roots are attached, removed ones are detached, and the checkout rules of the others are updated if they changed.
+func updateVcsRootEntries(rest *restClient, buildConfigID string, o *schema.Set, n *schema.Set) error {
+	path := fmt.Sprintf("buildTypes/%s/vcs-root-entries", api.LocatorID(buildConfigID))
+
+	old := make(map[string]*api.VcsRootEntry)
+	for _, raw := range o.List() {
+		e := buildVcsRootEntry(raw)
+		old[e.VcsRoot.ID] = e
+	}
+
+	for _, raw := range n.List() {
+		e := buildVcsRootEntry(raw)
+		id := e.VcsRoot.ID
+		attached, ok := old[id]
+		delete(old, id)
+
+		if !ok {
+			if err := rest.post(path, e, nil, "VCS root entry"); err != nil {
+				return err
+			}
+			log.Printf("[DEBUG] updateVcsRootEntries: attached vcsRoot '%s' to build configuration", id)
+		} else if attached.CheckoutRules != e.CheckoutRules {
+			e.ID = id
+			if err := rest.put(fmt.Sprintf("%s/%s", path, id), e, nil, "VCS root entry"); err != nil {
+				return err
+			}
+			log.Printf("[DEBUG] updateVcsRootEntries: updated checkout rules of vcsRoot '%s'", id)
+		}
+	}
+
+	for id := range old {
+		// Already detached outside of Terraform
+		if err := rest.delete(fmt.Sprintf("%s/%s", path, id), "VCS root entry"); err != nil && !isNotFoundError(err) {
+			return err
+		}
+		log.Printf("[DEBUG] updateVcsRootEntries: detached vcsRoot '%s' from build configuration", id)
+	}
+	return nil
+}
+
 func vcsRootHash(v interface{}) int {
 	raw := v.(map[string]interface{})
 	return schema.HashString(raw["id"].(string))
@@ -891,7 +2350,7 @@ func stepSetHash(v interface{}) int {
 		buf.WriteString(fmt.Sprintf("%s-", v.(string)))
 	}
 
-	return hashcode.String(buf.String())
+	return schema.HashString(buf.String())
 }
 
 func resourceBuildConfigInstanceResourceV0() *schema.Resource {
@@ -1043,7 +2502,7 @@ func resourceBuildConfigInstanceResourceV0() *schema.Resource {
 	}
 }
 
-func resourceBuildConfigInstanceStateUpgradeV0(rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+func resourceBuildConfigInstanceStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
 	if raw, ok := rawState["steps"]; ok {
 		s := raw.(*schema.Set)
 		list := s.List()
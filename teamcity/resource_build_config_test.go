@@ -3,13 +3,15 @@ package teamcity_test
 import (
 	"errors"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
 )
 
 func TestAccBuildConfig_Basic(t *testing.T) {
@@ -79,7 +81,9 @@ func TestAccBuildConfig_TemplateDisregardBuildCounter(t *testing.T) {
 				Config: TestAccBuildConfigTemplateBuildCounter,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckNoResourceAttr(resName, "settings.0.build_counter"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_counter": "0",
+					}),
 				),
 			},
 		},
@@ -109,7 +113,9 @@ func TestAccBuildConfig_ChangeToTemplate(t *testing.T) {
 				Config: TestAccBuildConfigChangeToTemplateUpdated,
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(resName, "is_template", "true"),
-					resource.TestCheckNoResourceAttr(resName, "settings.0.build_counter"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_counter": "0",
+					}),
 				),
 			},
 		},
@@ -155,14 +161,18 @@ func TestAccBuildConfig_UpdateBuildCounter(t *testing.T) {
 				Config: TestAccBuildConfigBuildCounter,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.3691833092.build_counter", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_counter": "2",
+					}),
 				),
 			},
 			{
 				Config: TestAccBuildConfigBuildCounterUpdated,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.1862934543.build_counter", "10"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_counter": "10",
+					}),
 				),
 			},
 			{
@@ -187,16 +197,20 @@ func TestAccBuildConfig_UpdateOtherSetting(t *testing.T) {
 				Config: TestAccBuildConfigBasic,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.2597056888.build_number_format", "2.0.%build.counter%"),
-					resource.TestCheckResourceAttr(resName, "settings.2597056888.build_counter", "0"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_number_format": "2.0.%build.counter%",
+						"build_counter":       "0",
+					}),
 				),
 			},
 			{
 				Config: TestAccBuildConfigBasicUpdated,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.1873735866.build_counter", "0"),
-					resource.TestCheckResourceAttr(resName, "settings.1873735866.build_number_format", "3.0.%build.counter%"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"build_counter":       "0",
+						"build_number_format": "3.0.%build.counter%",
+					}),
 				),
 			},
 		},
@@ -505,6 +519,360 @@ func TestAccBuildConfig_StepsCmdLineUpdateSteps(t *testing.T) {
 	})
 }
 
+func TestAccBuildConfig_StepsGradle(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsGradle,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttrSet(resName, "step.0.step_id"),
+					resource.TestCheckResourceAttr(resName, "step.0.type", "gradle"),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "build"),
+					resource.TestCheckResourceAttr(resName, "step.0.tasks", "clean build"),
+					resource.TestCheckResourceAttr(resName, "step.0.file", "build.gradle.kts"),
+					resource.TestCheckResourceAttr(resName, "step.0.working_dir", "service"),
+					resource.TestCheckResourceAttr(resName, "step.0.jdk_home", "%env.JDK_11%"),
+					resource.TestCheckResourceAttr(resName, "step.0.use_wrapper", "true"),
+					resource.TestCheckResourceAttr(resName, "step.0.args", "--info"),
+					resource.TestCheckResourceAttr(resName, "step.1.type", "gradle"),
+					resource.TestCheckResourceAttr(resName, "step.1.tasks", "publish"),
+					resource.TestCheckResourceAttr(resName, "step.1.use_wrapper", "false"),
+				),
+			},
+			{
+				Config: TestAccBuildConfigStepsGradleUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.#", "1"),
+					resource.TestCheckResourceAttr(resName, "step.0.tasks", "clean test"),
+					resource.TestCheckResourceAttr(resName, "step.0.use_wrapper", "false"),
+					resource.TestCheckResourceAttr(resName, "step.0.jdk_home", ""),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsMaven(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsMaven,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttrSet(resName, "step.0.step_id"),
+					resource.TestCheckResourceAttr(resName, "step.0.type", "maven"),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "package"),
+					resource.TestCheckResourceAttr(resName, "step.0.goals", "clean package"),
+					resource.TestCheckResourceAttr(resName, "step.0.file", "service/pom.xml"),
+					resource.TestCheckResourceAttr(resName, "step.0.args", "-DskipTests"),
+					resource.TestCheckResourceAttr(resName, "step.0.maven_version", "3.6.3"),
+					resource.TestCheckResourceAttr(resName, "step.0.user_settings", "settings.xml"),
+					resource.TestCheckResourceAttr(resName, "step.0.jdk_home", "%env.JDK_11%"),
+					resource.TestCheckResourceAttr(resName, "step.1.goals", "deploy"),
+					resource.TestCheckResourceAttr(resName, "step.1.maven_version", ""),
+					resource.TestCheckResourceAttr(resName, "step.1.user_settings", ""),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsDocker(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsDocker,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "step.0.type", "docker"),
+					resource.TestCheckResourceAttr(resName, "step.0.docker_command", "build"),
+					resource.TestCheckResourceAttr(resName, "step.0.file", "docker/Dockerfile"),
+					resource.TestCheckResourceAttr(resName, "step.0.context_dir", "src"),
+					resource.TestCheckResourceAttr(resName, "step.0.image_tags.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.0.image_tags.0", "registry.local/app:%build.number%"),
+					resource.TestCheckResourceAttr(resName, "step.0.image_tags.1", "registry.local/app:latest"),
+					resource.TestCheckResourceAttr(resName, "step.0.args", "--pull"),
+					resource.TestCheckResourceAttr(resName, "step.1.docker_command", "build"),
+					resource.TestCheckResourceAttr(resName, "step.1.code", "FROM alpine\nRUN echo hello\n"),
+					resource.TestCheckResourceAttr(resName, "step.2.docker_command", "push"),
+					resource.TestCheckResourceAttr(resName, "step.2.image_tags.0", "registry.local/app:%build.number%"),
+					resource.TestCheckResourceAttr(resName, "step.3.docker_command", "tag"),
+					resource.TestCheckResourceAttr(resName, "step.3.args", "registry.local/app:latest registry.local/app:stable"),
+					resource.TestCheckResourceAttr(resName, "step.4.type", "docker_compose"),
+					resource.TestCheckResourceAttr(resName, "step.4.compose_files.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.4.compose_files.1", "docker-compose.test.yml"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsDockerRequiresCommand(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      TestAccBuildConfigStepsDockerNoCommand,
+				ExpectError: regexp.MustCompile("docker_command is required for docker steps"),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsDotnet(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsDotnet,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "step.0.type", "dotnet"),
+					resource.TestCheckResourceAttr(resName, "step.0.dotnet_command", "build"),
+					resource.TestCheckResourceAttr(resName, "step.0.projects.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.0.projects.0", "src/Api/Api.csproj"),
+					resource.TestCheckResourceAttr(resName, "step.0.framework", "net5.0"),
+					resource.TestCheckResourceAttr(resName, "step.0.configuration", "Release"),
+					resource.TestCheckResourceAttr(resName, "step.0.verbosity", "Minimal"),
+					resource.TestCheckResourceAttr(resName, "step.0.args", "--no-restore"),
+					resource.TestCheckResourceAttr(resName, "step.1.dotnet_command", "test"),
+					resource.TestCheckResourceAttr(resName, "step.2.dotnet_command", "publish"),
+					resource.TestCheckResourceAttr(resName, "step.2.output_dir", "out"),
+					resource.TestCheckResourceAttr(resName, "step.3.dotnet_command", "nuget-push"),
+				),
+			},
+			{
+				Config: TestAccBuildConfigStepsDotnetUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.#", "1"),
+					resource.TestCheckResourceAttr(resName, "step.0.dotnet_command", "pack"),
+					resource.TestCheckResourceAttr(resName, "step.0.configuration", "Debug"),
+					resource.TestCheckResourceAttr(resName, "step.0.projects.#", "0"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsGeneric(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsGeneric,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "step.0.type", "generic"),
+					resource.TestCheckResourceAttr(resName, "step.0.runner_type", "Ant"),
+					resource.TestCheckResourceAttr(resName, "step.0.properties.%", "2"),
+					resource.TestCheckResourceAttr(resName, "step.0.properties.build-file-path", "build.xml"),
+					resource.TestCheckResourceAttr(resName, "step.0.properties.target", "dist"),
+					resource.TestCheckResourceAttr(resName, "step.1.runner_type", "python-runner"),
+				),
+			},
+			{
+				Config: TestAccBuildConfigStepsGenericUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.#", "1"),
+					resource.TestCheckResourceAttr(resName, "step.0.properties.%", "1"),
+					resource.TestCheckResourceAttr(resName, "step.0.properties.target", "test"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsExecution(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsExecution,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "step.0.execute_mode", "default"),
+					resource.TestCheckResourceAttr(resName, "step.0.enabled", "true"),
+					resource.TestCheckResourceAttr(resName, "step.0.conditions.#", "0"),
+					resource.TestCheckResourceAttr(resName, "step.1.execute_mode", "on_success"),
+					resource.TestCheckResourceAttr(resName, "step.1.enabled", "false"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.0.condition", "equals"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.0.name", "teamcity.build.branch"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.0.value", "main"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.1.condition", "exists"),
+					resource.TestCheckResourceAttr(resName, "step.2.execute_mode", "always"),
+				),
+			},
+			{
+				Config: TestAccBuildConfigStepsExecutionUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.1.execute_mode", "on_failure"),
+					resource.TestCheckResourceAttr(resName, "step.1.enabled", "true"),
+					resource.TestCheckResourceAttr(resName, "step.1.conditions.#", "0"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_StepsUpdateInPlace(t *testing.T) {
+	resName := "teamcity_build_config.build_configuration_test"
+	ids := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigStepsOrder,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStepIDsSaved(resName, ids),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "build"),
+					resource.TestCheckResourceAttr(resName, "step.1.name", "test"),
+					resource.TestCheckResourceAttr(resName, "step.2.name", "publish"),
+				),
+			},
+			{
+				// Changed steps keep their ids
+				Config: TestAccBuildConfigStepsOrderChanged,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.1.code", "make test-all"),
+					testAccCheckStepIDsKept(resName, ids, "build", "test", "publish"),
+				),
+			},
+			{
+				// Reordered steps keep their ids too
+				Config: TestAccBuildConfigStepsOrderReordered,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.0.name", "test"),
+					resource.TestCheckResourceAttr(resName, "step.1.name", "build"),
+					resource.TestCheckResourceAttr(resName, "step.2.name", "publish"),
+					testAccCheckStepIDsKept(resName, ids, "test", "build", "publish"),
+				),
+			},
+			{
+				// Removing and adding steps leaves the others alone
+				Config: TestAccBuildConfigStepsOrderReplaced,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "step.#", "3"),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "lint"),
+					resource.TestCheckResourceAttr(resName, "step.1.name", "test"),
+					resource.TestCheckResourceAttr(resName, "step.2.name", "publish"),
+					testAccCheckStepIDsKept(resName, ids, "", "test", "publish"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_TemplateSteps(t *testing.T) {
+	resName := "teamcity_build_config.build_configuration_test"
+	ids := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Steps inherited from the template aren't part of the build configuration steps
+				Config: fmt.Sprintf(TestAccBuildConfigTemplateSteps, "build", "make", "test", "make test"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStepIDsSaved(resName, ids),
+					resource.TestCheckResourceAttr(resName, "step.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "build"),
+					resource.TestCheckResourceAttr(resName, "step.1.name", "test"),
+				),
+			},
+			{
+				// Changing and reordering the own steps leaves the inherited step alone
+				Config: fmt.Sprintf(TestAccBuildConfigTemplateSteps, "test", "make test-all", "build", "make"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStepIDsKept(resName, ids, "test", "build"),
+					resource.TestCheckResourceAttr(resName, "step.#", "2"),
+					resource.TestCheckResourceAttr(resName, "step.0.name", "test"),
+					resource.TestCheckResourceAttr(resName, "step.0.code", "make test-all"),
+					resource.TestCheckResourceAttr(resName, "step.1.name", "build"),
+					resource.TestCheckResourceAttr("teamcity_build_config.template", "step.#", "1"),
+					resource.TestCheckResourceAttr("teamcity_build_config.template", "step.0.name", "setup"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
 func TestAccBuildConfig_Parameters(t *testing.T) {
 	var bc api.BuildType
 	resName := "teamcity_build_config.build_configuration_test"
@@ -576,15 +944,17 @@ func TestAccBuildConfig_Settings(t *testing.T) {
 				Config: TestAccBuildConfigSettings,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.configuration_type", "DEPLOYMENT"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.allow_personal_builds", "true"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.artifact_paths.#", "1"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.artifact_paths.0", "+:*.json => /config/*.json"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.build_counter", "20"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.build_number_format", "1.0.%build.counter%"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.concurrent_limit", "10"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.detect_hanging", "true"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.status_widget", "false"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"configuration_type":    "DEPLOYMENT",
+						"allow_personal_builds": "true",
+						"artifact_paths.#":      "1",
+						"artifact_paths.0":      "+:*.json => /config/*.json",
+						"build_counter":         "20",
+						"build_number_format":   "1.0.%build.counter%",
+						"concurrent_limit":      "10",
+						"detect_hanging":        "true",
+						"status_widget":         "false",
+					}),
 				),
 			},
 		},
@@ -603,30 +973,34 @@ func TestAccBuildConfig_SettingsUpdate(t *testing.T) {
 				Config: TestAccBuildConfigSettings,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.configuration_type", "DEPLOYMENT"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.allow_personal_builds", "true"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.artifact_paths.#", "1"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.artifact_paths.0", "+:*.json => /config/*.json"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.build_counter", "20"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.build_number_format", "1.0.%build.counter%"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.concurrent_limit", "10"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.detect_hanging", "true"),
-					resource.TestCheckResourceAttr(resName, "settings.3961488219.status_widget", "false"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"configuration_type":    "DEPLOYMENT",
+						"allow_personal_builds": "true",
+						"artifact_paths.#":      "1",
+						"artifact_paths.0":      "+:*.json => /config/*.json",
+						"build_counter":         "20",
+						"build_number_format":   "1.0.%build.counter%",
+						"concurrent_limit":      "10",
+						"detect_hanging":        "true",
+						"status_widget":         "false",
+					}),
 				),
 			},
 			{
 				Config: TestAccBuildConfigSettingsUpdated,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.configuration_type", "REGULAR"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.allow_personal_builds", "false"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.artifact_paths.#", "1"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.artifact_paths.0", "+:*.json => /artifacts/*.json"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.build_counter", "25"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.build_number_format", "2.0.%build.counter%"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.concurrent_limit", "0"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.detect_hanging", "false"),
-					resource.TestCheckResourceAttr(resName, "settings.3684435053.status_widget", "true"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "settings.*", map[string]string{
+						"configuration_type":    "REGULAR",
+						"allow_personal_builds": "false",
+						"artifact_paths.#":      "1",
+						"artifact_paths.0":      "+:*.json => /artifacts/*.json",
+						"build_counter":         "25",
+						"build_number_format":   "2.0.%build.counter%",
+						"concurrent_limit":      "0",
+						"detect_hanging":        "false",
+						"status_widget":         "true",
+					}),
 				),
 			},
 		},
@@ -652,70 +1026,411 @@ func TestAccBuildConfig_VcsRoot(t *testing.T) {
 	})
 }
 
-func TestAccBuildConfig_AttachTemplates(t *testing.T) {
-	var bc, t1, t2 api.BuildType
-	var t3 api.BuildType
+func TestAccBuildConfig_VcsRootUpdate(t *testing.T) {
+	var bc api.BuildType
 	resName := "teamcity_build_config.build_configuration_test"
-	template1 := "teamcity_build_config.build_configuration_template1"
-	template2 := "teamcity_build_config.build_configuration_template2"
-	template3 := "teamcity_build_config.build_configuration_template3"
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckBuildConfigDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: TestAccBuildConfigAttachTemplates,
+				Config: TestAccBuildConfigVcsRoots,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckBuildConfigExists(resName, &bc),
-					testAccCheckBuildConfigExists(template1, &t1),
-					testAccCheckBuildConfigExists(template2, &t2),
-					resource.TestCheckResourceAttr(resName, "templates.#", "2"),
-					resource.TestCheckResourceAttrPtr(resName, "templates.0", &t1.ID),
-					resource.TestCheckResourceAttrPtr(resName, "templates.1", &t2.ID),
+					resource.TestCheckResourceAttr(resName, "vcs_root.#", "2"),
+					testAccCheckVcsRootCount(&bc, 2),
+					testAccCheckVcsRootAttached(&bc.VcsRootEntries, "application", "+:*\\n-:README.MD"),
+					testAccCheckVcsRootAttached(&bc.VcsRootEntries, "library", ""),
 				),
 			},
 			{
-				Config: TestAccBuildConfigUpdatedTemplates,
+				Config: TestAccBuildConfigVcsRootsUpdated,
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckBuildConfigExists(template3, &t3),
-					resource.TestCheckResourceAttr(resName, "templates.#", "2"),
-					resource.TestCheckResourceAttrPtr(resName, "templates.0", &t1.ID),
-					resource.TestCheckResourceAttrPtr(resName, "templates.1", &t3.ID),
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "vcs_root.#", "1"),
+					testAccCheckVcsRootCount(&bc, 1),
+					testAccCheckVcsRootAttached(&bc.VcsRootEntries, "application", "+:src"),
+				),
+			},
+			{
+				Config: TestAccBuildConfigVcsRootsDetached,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "vcs_root.#", "0"),
+					testAccCheckVcsRootCount(&bc, 0),
 				),
 			},
 		},
 	})
 }
 
-func testAccCheckStepRemoved(buildTypeID *string, stepRemoved map[string]string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
-		exists, _ := testStepExists(client, *buildTypeID, stepRemoved)
-		if exists {
-			return fmt.Errorf("expected step %s to be removed, but still exists", stepRemoved["name"])
-		}
-		return nil
-	}
-}
+func TestAccBuildConfig_TypedParameters(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
 
-func testAccCheckStepExists(buildTypeID *string, stepExpected map[string]string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
-		_, err := testStepExists(client, *buildTypeID, stepExpected)
-		return err
-	}
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigTypedParameters,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "parameter.#", "4"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":               "version",
+						"value":              "1",
+						"type":               "text",
+						"label":              "Version",
+						"display":            "prompt",
+						"regex":              "^\\d+$",
+						"validation_message": "A number",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":  "env.DEPLOY_TOKEN",
+						"value": testAccPasswordHash("s3cr3t"),
+						"type":  "password",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":            "deploy",
+						"value":           "no",
+						"type":            "checkbox",
+						"checked_value":   "yes",
+						"unchecked_value": "no",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":           "system.environments",
+						"value":          "dev",
+						"type":           "select",
+						"description":    "Where to deploy",
+						"options.#":      "2",
+						"options.0":      "dev",
+						"options.1":      "prod",
+						"allow_multiple": "true",
+					}),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value1"),
+					resource.TestCheckNoResourceAttr(resName, "config_params.version"),
+				),
+			},
+			{
+				// Updating plain parameters must keep the specification of typed ones
+				Config: TestAccBuildConfigTypedParametersUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "parameter.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":      "system.environments",
+						"options.#": "3",
+						"options.2": "staging",
+					}),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value2"),
+				),
+			},
+		},
+	})
 }
 
-func testStepExists(client *api.Client, buildTypeID string, stepExpected map[string]string) (bool, error) {
-	steps, err := client.BuildTypes.GetSteps(buildTypeID)
-	if err != nil {
-		return false, fmt.Errorf("error when checking steps: %s", err)
-	}
-
-	for _, v := range steps {
-		if v.GetName() == stepExpected["name"] {
-			err := assertStepProperties(v, stepExpected)
+func TestAccBuildConfig_TypedParameterConflict(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      TestAccBuildConfigTypedParameterConflict,
+				ExpectError: regexp.MustCompile("parameter 'env.TOKEN' is defined both in a parameter block and in a parameters map"),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_PasswordParams(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigPasswordParams, "config_value1", "s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "password_params.%", "2"),
+					resource.TestCheckResourceAttr(resName, "password_params.env.DEPLOY_TOKEN", testAccPasswordHash("s3cr3t")),
+					resource.TestCheckResourceAttr(resName, "password_params.system.registry.password", testAccPasswordHash("hunter2")),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value1"),
+				),
+			},
+			{
+				// Plain parameters are written by the api client, which must not lose the passwords
+				Config: fmt.Sprintf(TestAccBuildConfigPasswordParams, "config_value2", "s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "password_params.%", "2"),
+					resource.TestCheckResourceAttr(resName, "password_params.env.DEPLOY_TOKEN", testAccPasswordHash("s3cr3t")),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value2"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigPasswordParams, "config_value2", "n3w-s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "password_params.env.DEPLOY_TOKEN", testAccPasswordHash("n3w-s3cr3t")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_InheritedParams(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigInheritedParams,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "config_params.%", "2"),
+					resource.TestCheckResourceAttr(resName, "config_params.own", "build_config"),
+					resource.TestCheckResourceAttr(resName, "config_params.overridden", "build_config"),
+					resource.TestCheckResourceAttr(resName, "env_params.%", "0"),
+					resource.TestCheckResourceAttr(resName, "effective_params.%", "5"),
+					resource.TestCheckResourceAttr(resName, "effective_params.own", "build_config"),
+					resource.TestCheckResourceAttr(resName, "effective_params.overridden", "build_config"),
+					resource.TestCheckResourceAttr(resName, "effective_params.from_template", "template"),
+					resource.TestCheckResourceAttr(resName, "effective_params.env.FROM_TEMPLATE", "template"),
+					resource.TestCheckResourceAttr(resName, "effective_params.from_project", "project"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_FailureConditions(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigFailureConditionsDefault,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.execution_timeout", "0"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_exit_code", "true"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_test_failure", "true"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_crash", "true"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_error_message", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigFailureConditions, "build config test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.execution_timeout", "30"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_exit_code", "false"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_test_failure", "true"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_crash", "false"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_error_message", "true"),
+				),
+			},
+			{
+				// The api client writes all the settings at once, which must keep the failure conditions
+				Config: fmt.Sprintf(TestAccBuildConfigFailureConditions, "build config test renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.execution_timeout", "30"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.fail_on_crash", "false"),
+					resource.TestCheckResourceAttr(resName, "settings.0.build_number_format", "2.%build.counter%"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_VcsSettings(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigFailureConditionsDefault,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_mode", "auto"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_dir", ""),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.clean_build", "false"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.show_dependencies_changes", "false"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.exclude_default_branch_changes", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigVcsSettings, "build config test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_mode", "on_agent"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_dir", "src/app"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.clean_build", "true"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.show_dependencies_changes", "true"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.exclude_default_branch_changes", "true"),
+				),
+			},
+			{
+				// The api client writes all the settings at once, which must keep the VCS settings
+				Config: fmt.Sprintf(TestAccBuildConfigVcsSettings, "build config test renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_mode", "on_agent"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.clean_build", "true"),
+					resource.TestCheckResourceAttr(resName, "failure_conditions.0.execution_timeout", "15"),
+				),
+			},
+			{
+				// Without checkout_mode, the mode of the build configuration is kept
+				Config: TestAccBuildConfigVcsSettingsNoCheckoutMode,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.checkout_mode", "on_agent"),
+					resource.TestCheckResourceAttr(resName, "vcs_settings.0.clean_build", "false"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_Paused(t *testing.T) {
+	var bc api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigPaused, "true"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					resource.TestCheckResourceAttr(resName, "paused", "true"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: fmt.Sprintf(TestAccBuildConfigPaused, "false"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "paused", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_PausedTemplate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      TestAccBuildConfigPausedTemplate,
+				ExpectError: regexp.MustCompile("'paused' field is not supported for Build Configuration Templates"),
+			},
+		},
+	})
+}
+
+func TestAccBuildConfig_AttachTemplates(t *testing.T) {
+	var bc, t1, t2 api.BuildType
+	var t3 api.BuildType
+	resName := "teamcity_build_config.build_configuration_test"
+	template1 := "teamcity_build_config.build_configuration_template1"
+	template2 := "teamcity_build_config.build_configuration_template2"
+	template3 := "teamcity_build_config.build_configuration_template3"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBuildConfigAttachTemplates,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(resName, &bc),
+					testAccCheckBuildConfigExists(template1, &t1),
+					testAccCheckBuildConfigExists(template2, &t2),
+					resource.TestCheckResourceAttr(resName, "templates.#", "2"),
+					resource.TestCheckResourceAttrPtr(resName, "templates.0", &t1.ID),
+					resource.TestCheckResourceAttrPtr(resName, "templates.1", &t2.ID),
+				),
+			},
+			{
+				Config: TestAccBuildConfigUpdatedTemplates,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists(template3, &t3),
+					resource.TestCheckResourceAttr(resName, "templates.#", "2"),
+					resource.TestCheckResourceAttrPtr(resName, "templates.0", &t1.ID),
+					resource.TestCheckResourceAttrPtr(resName, "templates.1", &t3.ID),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckStepRemoved(buildTypeID *string, stepRemoved map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
+		exists, _ := testStepExists(client, *buildTypeID, stepRemoved)
+		if exists {
+			return fmt.Errorf("expected step %s to be removed, but still exists", stepRemoved["name"])
+		}
+		return nil
+	}
+}
+
+func testAccCheckStepExists(buildTypeID *string, stepExpected map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
+		_, err := testStepExists(client, *buildTypeID, stepExpected)
+		return err
+	}
+}
+
+func testStepExists(client *api.Client, buildTypeID string, stepExpected map[string]string) (bool, error) {
+	steps, err := client.BuildTypes.GetSteps(buildTypeID)
+	if err != nil {
+		return false, fmt.Errorf("error when checking steps: %s", err)
+	}
+
+	for _, v := range steps {
+		// Steps of runner types the api client doesn't model are read as nil
+		if v != nil && v.GetName() == stepExpected["name"] {
+			err := assertStepProperties(v, stepExpected)
 			return err != nil, err
 		}
 	}
@@ -776,6 +1491,55 @@ func assertStepProperties(actual api.Step, expected map[string]string) error {
 	return fmt.Errorf("unexpected step type found: %s", stepType)
 }
 
+// testAccCheckStepIDsSaved saves the ids of the steps by name
+func testAccCheckStepIDsSaved(n string, ids map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+		count, _ := strconv.Atoi(rs.Primary.Attributes["step.#"])
+		for i := 0; i < count; i++ {
+			ids[rs.Primary.Attributes[fmt.Sprintf("step.%d.name", i)]] = rs.Primary.Attributes[fmt.Sprintf("step.%d.step_id", i)]
+		}
+		return nil
+	}
+}
+
+// testAccCheckStepIDsKept checks the steps, in order, have the ids saved for their names. Empty names are for new steps, whose ids must be new.
+func testAccCheckStepIDsKept(n string, ids map[string]string, names ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+		for i, name := range names {
+			actual := rs.Primary.Attributes[fmt.Sprintf("step.%d.step_id", i)]
+			if name == "" {
+				for saved, id := range ids {
+					if id == actual {
+						return fmt.Errorf("expected step %d to be new, but it has the id of step '%s': %s", i, saved, id)
+					}
+				}
+				continue
+			}
+			if actual != ids[name] {
+				return fmt.Errorf("expected step '%s' to keep id %s, got %s", name, ids[name], actual)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckVcsRootCount(bc *api.BuildType, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if len(bc.VcsRootEntries) != expected {
+			return fmt.Errorf("expected %d VCS roots attached, found %d", expected, len(bc.VcsRootEntries))
+		}
+		return nil
+	}
+}
+
 func testAccCheckVcsRootAttached(vcs *[]*api.VcsRootEntry, n string, co string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		if *vcs == nil {
@@ -796,13 +1560,13 @@ func testAccCheckVcsRootAttached(vcs *[]*api.VcsRootEntry, n string, co string)
 
 func testAccCheckBuildConfigExists(n string, out *api.BuildType) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return buildConfigExistsHelper(n, s, client, out)
 	}
 }
 
 func updateBuildCounter(buildType *api.BuildType, counter int) {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	id := buildType.ID
 
 	bt, err := client.BuildTypes.GetByID(id)
@@ -839,7 +1603,7 @@ func buildConfigExistsHelper(n string, s *terraform.State, client *api.Client, o
 }
 
 func testAccCheckBuildConfigDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	return buildConfigDestroyHelper(s, client)
 }
 
@@ -956,19 +1720,862 @@ resource "teamcity_build_config" "build_configuration_test" {
 }
 `
 
-const TestAccBuildConfigTemplate = `
+const TestAccBuildConfigTemplate = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config template"
+	is_template = "true"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+}
+`
+
+const TestAccBuildConfigBasicUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	description = "build config test desc updated"
+	settings {
+		build_number_format = "3.0.%build.counter%"
+	}
+}
+`
+const TestAccBuildConfigBuildCounter = `
+resource "teamcity_project" "project" {
+  name = "project"
+}
+
+resource "teamcity_build_config" "build_config" {
+	name = "build config test"
+	project_id = "${teamcity_project.project.id}"
+	description = "build config test desc"
+	settings {
+		build_counter = 2
+	}
+}
+`
+
+const TestAccBuildConfigBuildCounterUpdated = `
+resource "teamcity_project" "project" {
+	name = "project"
+  }
+
+  resource "teamcity_build_config" "build_config" {
+	  name = "build config test"
+	  project_id = "${teamcity_project.project.id}"
+	  description = "build config test desc"
+	  settings {
+		build_counter = 10
+	  }
+  }
+`
+const TestAccBuildConfigParams = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	env_params = {
+		DEPLOY_SERVER = "server.com"
+		some_variable = "hello"
+	}
+
+	config_params = {
+		"github.repository" = "nocode"
+	}
+
+	sys_params = {
+		system_param = "system_value"
+	}
+}
+`
+
+const TestAccBuildConfigParamsUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	env_params = {
+		DEPLOY_SERVER = "server.com"
+		some_variable = "hello"
+	}
+
+	config_params = {
+		"github.repository" = "updated_repo"
+	}
+}
+`
+
+const TestAccBuildConfigSettings = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+  name = "build config test"
+  project_id = "${teamcity_project.build_config_project_test.id}"
+
+  settings {
+	configuration_type = "DEPLOYMENT"
+    build_number_format = "1.0.%build.counter%"
+    build_counter = 20
+    allow_personal_builds = true
+    artifact_paths = ["+:*.json => /config/*.json"]
+    detect_hanging = true
+    status_widget = false
+    concurrent_limit = 10
+  }
+}
+`
+
+const TestAccBuildConfigSettingsUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+  name = "build config test"
+  project_id = "${teamcity_project.build_config_project_test.id}"
+
+  settings {
+	configuration_type = "REGULAR"
+    build_number_format = "2.0.%build.counter%"
+    build_counter = 25
+    allow_personal_builds = false
+    artifact_paths = ["+:*.json => /artifacts/*.json"]
+    detect_hanging = false
+    status_widget = true
+    concurrent_limit = 0
+  }
+}
+`
+
+const TestAccBuildConfigVcsRoot = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_test" {
+	name = "application"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	vcs_root {
+		id = "${teamcity_vcs_root_git.build_config_vcsroot_test.id}"
+		checkout_rules = ["+:*", "-:README.MD"]
+	}
+}
+`
+
+const TestAccBuildConfigVcsRoots = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_test" {
+	name = "application"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_library" {
+	name = "library"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	vcs_root {
+		id = "${teamcity_vcs_root_git.build_config_vcsroot_test.id}"
+		checkout_rules = ["+:*", "-:README.MD"]
+	}
+
+	vcs_root {
+		id = "${teamcity_vcs_root_git.build_config_vcsroot_library.id}"
+	}
+}
+`
+
+const TestAccBuildConfigVcsRootsUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_test" {
+	name = "application"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_library" {
+	name = "library"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	vcs_root {
+		id = "${teamcity_vcs_root_git.build_config_vcsroot_test.id}"
+		checkout_rules = ["+:src"]
+	}
+}
+`
+
+const TestAccBuildConfigVcsRootsDetached = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_test" {
+	name = "application"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_vcs_root_git" "build_config_vcsroot_library" {
+	name = "library"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	fetch_url = "https://github.com/kelseyhightower/nocode"
+	default_branch = "refs/head/master"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+}
+`
+
+const TestAccBuildConfigAttachTemplates = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = teamcity_project.build_config_project_test.id
+
+	templates = [ teamcity_build_config.build_configuration_template1.id, teamcity_build_config.build_configuration_template2.id ]
+
+	depends_on = [
+		teamcity_build_config.build_configuration_template1,
+		teamcity_build_config.build_configuration_template2,
+	]
+}
+
+resource "teamcity_build_config" "build_configuration_template1" {
+	name = "build template 1"
+	is_template = true
+	project_id = teamcity_project.build_config_project_test.id
+}
+
+resource "teamcity_build_config" "build_configuration_template2" {
+	name = "build template 2"
+	is_template = true
+	project_id = teamcity_project.build_config_project_test.id
+}
+`
+
+const TestAccBuildConfigUpdatedTemplates = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = teamcity_project.build_config_project_test.id
+
+	templates = [ teamcity_build_config.build_configuration_template1.id, teamcity_build_config.build_configuration_template3.id ]
+}
+
+resource "teamcity_build_config" "build_configuration_template1" {
+	name = "build template 1"
+	is_template = true
+	project_id = teamcity_project.build_config_project_test.id
+}
+
+# Need to keep this around here or Terraform is trying to destroy template2 before updating the dependant resource
+resource "teamcity_build_config" "build_configuration_template2" {
+	name = "build template 2"
+	is_template = true
+	project_id = teamcity_project.build_config_project_test.id
+}
+
+resource "teamcity_build_config" "build_configuration_template3" {
+	name = "build template 3"
+	is_template = true
+	project_id = teamcity_project.build_config_project_test.id
+}
+`
+
+const TestAccBuildConfigStepsPowershell = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "powershell"
+		name = "build_script"
+		file = "build.ps1"
+		args = "-Target buildrelease"
+	}
+
+	step {
+		type = "powershell"
+		name = "build_code"
+		code = "Get-Date"
+	}
+}
+`
+
+const TestAccBuildConfigStepsPowershellUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "powershell"
+		name = "updated_script"
+		file = "updated.ps1"
+		args = "-Target pullrequest"
+	}
+}
+`
+
+const TestAccBuildConfigStepsCmdLine = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build_script"
+		code = "echo \"Hello World\""
+	}
+
+	step {
+		type = "cmd_line"
+		name = "build_executable"
+		file = "./build.sh"
+		args = "default_target --verbose"
+	}
+}
+`
+
+const TestAccBuildConfigStepsCmdLineUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build_script"
+		code = "echo \"Hello Foo\""
+	}
+
+	step {
+		type = "cmd_line"
+		name = "build_executable"
+		file = "./build.sh"
+		args = "default_target --verbose"
+	}
+}
+`
+
+const TestAccBuildConfigStepsGradle = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "gradle"
+		name = "build"
+		tasks = "clean build"
+		file = "build.gradle.kts"
+		working_dir = "service"
+		jdk_home = "%env.JDK_11%"
+		use_wrapper = true
+		args = "--info"
+	}
+
+	step {
+		type = "gradle"
+		name = "publish"
+		tasks = "publish"
+	}
+}
+`
+
+const TestAccBuildConfigStepsGradleUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "gradle"
+		name = "build"
+		tasks = "clean test"
+		file = "build.gradle.kts"
+	}
+}
+`
+
+const TestAccBuildConfigStepsMaven = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "maven"
+		name = "package"
+		goals = "clean package"
+		file = "service/pom.xml"
+		args = "-DskipTests"
+		maven_version = "3.6.3"
+		user_settings = "settings.xml"
+		jdk_home = "%env.JDK_11%"
+	}
+
+	step {
+		type = "maven"
+		name = "deploy"
+		goals = "deploy"
+	}
+}
+`
+
+const TestAccBuildConfigStepsDocker = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "docker"
+		name = "build_image"
+		docker_command = "build"
+		file = "docker/Dockerfile"
+		context_dir = "src"
+		image_tags = ["registry.local/app:%build.number%", "registry.local/app:latest"]
+		args = "--pull"
+	}
+
+	step {
+		type = "docker"
+		name = "build_inline"
+		docker_command = "build"
+		code = "FROM alpine\nRUN echo hello\n"
+	}
+
+	step {
+		type = "docker"
+		name = "push_image"
+		docker_command = "push"
+		image_tags = ["registry.local/app:%build.number%"]
+	}
+
+	step {
+		type = "docker"
+		name = "tag_stable"
+		docker_command = "tag"
+		args = "registry.local/app:latest registry.local/app:stable"
+	}
+
+	step {
+		type = "docker_compose"
+		name = "services"
+		compose_files = ["docker-compose.yml", "docker-compose.test.yml"]
+	}
+}
+`
+
+const TestAccBuildConfigStepsDockerNoCommand = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "docker"
+		file = "Dockerfile"
+	}
+}
+`
+
+const TestAccBuildConfigStepsDotnet = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "dotnet"
+		name = "build"
+		dotnet_command = "build"
+		projects = ["src/Api/Api.csproj", "src/Worker/Worker.csproj"]
+		framework = "net5.0"
+		configuration = "Release"
+		verbosity = "Minimal"
+		args = "--no-restore"
+	}
+
+	step {
+		type = "dotnet"
+		name = "test"
+		dotnet_command = "test"
+		projects = ["tests/Api.Tests/Api.Tests.csproj"]
+	}
+
+	step {
+		type = "dotnet"
+		name = "publish"
+		dotnet_command = "publish"
+		projects = ["src/Api/Api.csproj"]
+		output_dir = "out"
+	}
+
+	step {
+		type = "dotnet"
+		name = "push"
+		dotnet_command = "nuget-push"
+		projects = ["out/*.nupkg"]
+	}
+}
+`
+
+const TestAccBuildConfigStepsDotnetUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "dotnet"
+		name = "pack"
+		dotnet_command = "pack"
+		configuration = "Debug"
+	}
+}
+`
+
+const TestAccBuildConfigStepsGeneric = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "generic"
+		name = "ant"
+		runner_type = "Ant"
+		properties = {
+			"build-file-path" = "build.xml"
+			"target" = "dist"
+		}
+	}
+
+	step {
+		type = "generic"
+		name = "python"
+		runner_type = "python-runner"
+		properties = {
+			"python-script-code" = "print('hello')"
+			"python-exe" = "%AnyPython%"
+		}
+	}
+}
+`
+
+const TestAccBuildConfigStepsGenericUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "generic"
+		name = "ant"
+		runner_type = "Ant"
+		properties = {
+			"target" = "test"
+		}
+	}
+}
+`
+
+const TestAccBuildConfigStepsExecution = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build"
+		code = "make"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "deploy"
+		code = "make deploy"
+		execute_mode = "on_success"
+		enabled = false
+
+		conditions {
+			condition = "equals"
+			name = "teamcity.build.branch"
+			value = "main"
+		}
+
+		conditions {
+			condition = "exists"
+			name = "env.DEPLOY_TOKEN"
+		}
+	}
+
+	step {
+		type = "cmd_line"
+		name = "cleanup"
+		code = "make clean"
+		execute_mode = "always"
+	}
+}
+`
+
+const TestAccBuildConfigStepsExecutionUpdated = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build"
+		code = "make"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "deploy"
+		code = "make deploy"
+		execute_mode = "on_failure"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "cleanup"
+		code = "make clean"
+		execute_mode = "always"
+	}
+}
+`
+
+const TestAccBuildConfigStepsOrder = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build"
+		code = "make"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "test"
+		code = "make test"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "publish"
+		code = "make publish"
+	}
+}
+`
+
+const TestAccBuildConfigTemplateSteps = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "template" {
+	name = "template"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	is_template = true
+
+	step {
+		type = "cmd_line"
+		name = "setup"
+		code = "make setup"
+	}
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	templates = ["${teamcity_build_config.template.id}"]
+
+	step {
+		type = "cmd_line"
+		name = "%s"
+		code = "%s"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "%s"
+		code = "%s"
+	}
+}
+`
+
+const TestAccBuildConfigStepsOrderChanged = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
+}
+
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "build"
+		code = "make"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "test"
+		code = "make test-all"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "publish"
+		code = "make publish"
+	}
+}
+`
+
+const TestAccBuildConfigStepsOrderReordered = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
-	name = "build config template"
-	is_template = "true"
+	name = "build config test"
 	project_id = "${teamcity_project.build_config_project_test.id}"
+
+	step {
+		type = "cmd_line"
+		name = "test"
+		code = "make test-all"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "build"
+		code = "make"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "publish"
+		code = "make publish"
+	}
 }
 `
 
-const TestAccBuildConfigBasicUpdated = `
+const TestAccBuildConfigStepsOrderReplaced = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
@@ -976,42 +2583,44 @@ resource "teamcity_project" "build_config_project_test" {
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
 	project_id = "${teamcity_project.build_config_project_test.id}"
-	description = "build config test desc updated"
-	settings {
-		build_number_format = "3.0.%build.counter%"
+
+	step {
+		type = "cmd_line"
+		name = "lint"
+		code = "make lint"
 	}
-}
-`
-const TestAccBuildConfigBuildCounter = `
-resource "teamcity_project" "project" {
-  name = "project"
-}
 
-resource "teamcity_build_config" "build_config" {
-	name = "build config test"
-	project_id = "${teamcity_project.project.id}"
-	description = "build config test desc"
-	settings {
-		build_counter = 2
+	step {
+		type = "cmd_line"
+		name = "test"
+		code = "make test-all"
+	}
+
+	step {
+		type = "cmd_line"
+		name = "publish"
+		code = "make publish"
 	}
 }
 `
 
-const TestAccBuildConfigBuildCounterUpdated = `
-resource "teamcity_project" "project" {
-	name = "project"
-  }
+const TestAccBuildConfigurationIdWithParent = `
+resource "teamcity_project" "parent" {
+	name = "parent"
+}
 
-  resource "teamcity_build_config" "build_config" {
-	  name = "build config test"
-	  project_id = "${teamcity_project.project.id}"
-	  description = "build config test desc"
-	  settings {
-		build_counter = 10
-	  }
-  }
+resource "teamcity_project" "child" {
+	name = "child"
+	parent_id = "${teamcity_project.parent.id}"
+}
+
+resource teamcity_build_config "build_config" {
+	name = "build config"
+	project_id = "${teamcity_project.child.id}"
+}
 `
-const TestAccBuildConfigParams = `
+
+const TestAccBuildConfigTypedParameters = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
@@ -1020,22 +2629,45 @@ resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
 	project_id = "${teamcity_project.build_config_project_test.id}"
 
-	env_params = {
-		DEPLOY_SERVER = "server.com"
-		some_variable = "hello"
+	config_params = {
+		param1 = "config_value1"
 	}
 
-	config_params = {
-		"github.repository" = "nocode"
+	parameter {
+		name = "version"
+		value = "1"
+		label = "Version"
+		display = "prompt"
+		regex = "^\\d+$"
+		validation_message = "A number"
 	}
 
-	sys_params = {
-		system_param = "system_value"
+	parameter {
+		name = "env.DEPLOY_TOKEN"
+		value = "s3cr3t"
+		type = "password"
+	}
+
+	parameter {
+		name = "deploy"
+		value = "no"
+		type = "checkbox"
+		checked_value = "yes"
+		unchecked_value = "no"
+	}
+
+	parameter {
+		name = "system.environments"
+		value = "dev"
+		type = "select"
+		description = "Where to deploy"
+		options = ["dev", "prod"]
+		allow_multiple = true
 	}
 }
 `
 
-const TestAccBuildConfigParamsUpdated = `
+const TestAccBuildConfigTypedParametersUpdated = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
@@ -1044,213 +2676,177 @@ resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
 	project_id = "${teamcity_project.build_config_project_test.id}"
 
-	env_params = {
-		DEPLOY_SERVER = "server.com"
-		some_variable = "hello"
+	config_params = {
+		param1 = "config_value2"
 	}
 
-	config_params = {
-		"github.repository" = "updated_repo"
+	parameter {
+		name = "system.environments"
+		value = "dev"
+		type = "select"
+		description = "Where to deploy"
+		options = ["dev", "prod", "staging"]
+		allow_multiple = true
 	}
 }
 `
 
-const TestAccBuildConfigSettings = `
+const TestAccBuildConfigTypedParameterConflict = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
-  name = "build config test"
-  project_id = "${teamcity_project.build_config_project_test.id}"
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
 
-  settings {
-	configuration_type = "DEPLOYMENT"
-    build_number_format = "1.0.%build.counter%"
-    build_counter = 20
-    allow_personal_builds = true
-    artifact_paths = ["+:*.json => /config/*.json"]
-    detect_hanging = true
-    status_widget = false
-    concurrent_limit = 10
-  }
+	env_params = {
+		TOKEN = "plain"
+	}
+
+	parameter {
+		name = "env.TOKEN"
+		type = "password"
+		value = "s3cr3t"
+	}
 }
 `
 
-const TestAccBuildConfigSettingsUpdated = `
+const TestAccBuildConfigPasswordParams = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
-  name = "build config test"
-  project_id = "${teamcity_project.build_config_project_test.id}"
+	name = "build config test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
 
-  settings {
-	configuration_type = "REGULAR"
-    build_number_format = "2.0.%build.counter%"
-    build_counter = 25
-    allow_personal_builds = false
-    artifact_paths = ["+:*.json => /artifacts/*.json"]
-    detect_hanging = false
-    status_widget = true
-    concurrent_limit = 0
-  }
+	config_params = {
+		param1 = "%s"
+	}
+
+	password_params = {
+		"env.DEPLOY_TOKEN" = "%s"
+		"system.registry.password" = "hunter2"
+	}
 }
 `
 
-const TestAccBuildConfigVcsRoot = `
+const TestAccBuildConfigInheritedParams = `
 resource "teamcity_project" "build_config_project_test" {
-  name = "build_config_project_test"
-}
+	name = "build_config_project_test"
 
-resource "teamcity_vcs_root_git" "build_config_vcsroot_test" {
-	name = "application"
-	project_id = "${teamcity_project.build_config_project_test.id}"
-	fetch_url = "https://github.com/kelseyhightower/nocode"
-	default_branch = "refs/head/master"
+	config_params = {
+		from_project = "project"
+	}
 }
 
-resource "teamcity_build_config" "build_configuration_test" {
-	name = "build config test"
+resource "teamcity_build_config" "template" {
+	name = "template"
 	project_id = "${teamcity_project.build_config_project_test.id}"
+	is_template = true
 
-	vcs_root {
-		id = "${teamcity_vcs_root_git.build_config_vcsroot_test.id}"
-		checkout_rules = ["+:*", "-:README.MD"]
+	config_params = {
+		from_template = "template"
+		overridden = "template"
 	}
-}
-`
 
-const TestAccBuildConfigAttachTemplates = `
-resource "teamcity_project" "build_config_project_test" {
-  name = "build_config_project_test"
+	env_params = {
+		FROM_TEMPLATE = "template"
+	}
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
-	project_id = teamcity_project.build_config_project_test.id
-
-	templates = [ teamcity_build_config.build_configuration_template1.id, teamcity_build_config.build_configuration_template2.id ]
-
-	depends_on = [
-		teamcity_build_config.build_configuration_template1,
-		teamcity_build_config.build_configuration_template2,
-	]
-}
-
-resource "teamcity_build_config" "build_configuration_template1" {
-	name = "build template 1"
-	is_template = true
-	project_id = teamcity_project.build_config_project_test.id
-}
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	templates = ["${teamcity_build_config.template.id}"]
 
-resource "teamcity_build_config" "build_configuration_template2" {
-	name = "build template 2"
-	is_template = true
-	project_id = teamcity_project.build_config_project_test.id
+	config_params = {
+		own = "build_config"
+		overridden = "build_config"
+	}
 }
 `
 
-const TestAccBuildConfigUpdatedTemplates = `
+const TestAccBuildConfigFailureConditionsDefault = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
-	project_id = teamcity_project.build_config_project_test.id
-
-	templates = [ teamcity_build_config.build_configuration_template1.id, teamcity_build_config.build_configuration_template3.id ]
-}
-
-resource "teamcity_build_config" "build_configuration_template1" {
-	name = "build template 1"
-	is_template = true
-	project_id = teamcity_project.build_config_project_test.id
-}
-
-# Need to keep this around here or Terraform is trying to destroy template2 before updating the dependant resource
-resource "teamcity_build_config" "build_configuration_template2" {
-	name = "build template 2"
-	is_template = true
-	project_id = teamcity_project.build_config_project_test.id
-}
-
-resource "teamcity_build_config" "build_configuration_template3" {
-	name = "build template 3"
-	is_template = true
-	project_id = teamcity_project.build_config_project_test.id
+	project_id = "${teamcity_project.build_config_project_test.id}"
 }
 `
 
-const TestAccBuildConfigStepsPowershell = `
+const TestAccBuildConfigFailureConditions = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
+	description = "%s"
 	project_id = "${teamcity_project.build_config_project_test.id}"
 
-	step {
-		type = "powershell"
-		name = "build_script"
-		file = "build.ps1"
-		args = "-Target buildrelease"
+	settings {
+		build_number_format = "2.%%build.counter%%"
 	}
 
-	step {
-		type = "powershell"
-		name = "build_code"
-		code = "Get-Date"
+	failure_conditions {
+		execution_timeout = 30
+		fail_on_exit_code = false
+		fail_on_crash = false
+		fail_on_error_message = true
 	}
 }
 `
 
-const TestAccBuildConfigStepsPowershellUpdated = `
+const TestAccBuildConfigVcsSettings = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
+	description = "%s"
 	project_id = "${teamcity_project.build_config_project_test.id}"
 
-	step {
-		type = "powershell"
-		name = "updated_script"
-		file = "updated.ps1"
-		args = "-Target pullrequest"
+	failure_conditions {
+		execution_timeout = 15
+	}
+
+	vcs_settings {
+		checkout_mode = "on_agent"
+		checkout_dir = "src/app"
+		clean_build = true
+		show_dependencies_changes = true
+		exclude_default_branch_changes = true
 	}
 }
 `
 
-const TestAccBuildConfigStepsCmdLine = `
+const TestAccBuildConfigVcsSettingsNoCheckoutMode = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
 
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
+	description = "build config test renamed"
 	project_id = "${teamcity_project.build_config_project_test.id}"
 
-	step {
-		type = "cmd_line"
-		name = "build_script"
-		code = "echo \"Hello World\""
+	failure_conditions {
+		execution_timeout = 15
 	}
 
-	step {
-		type = "cmd_line"
-		name = "build_executable"
-		file = "./build.sh"
-		args = "default_target --verbose"
+	vcs_settings {
+		checkout_dir = "src/app"
 	}
 }
 `
 
-const TestAccBuildConfigStepsCmdLineUpdated = `
+const TestAccBuildConfigPaused = `
 resource "teamcity_project" "build_config_project_test" {
   name = "build_config_project_test"
 }
@@ -1258,34 +2854,19 @@ resource "teamcity_project" "build_config_project_test" {
 resource "teamcity_build_config" "build_configuration_test" {
 	name = "build config test"
 	project_id = "${teamcity_project.build_config_project_test.id}"
-
-	step {
-		type = "cmd_line"
-		name = "build_script"
-		code = "echo \"Hello Foo\""
-	}
-
-	step {
-		type = "cmd_line"
-		name = "build_executable"
-		file = "./build.sh"
-		args = "default_target --verbose"
-	}
+	paused = %s
 }
 `
 
-const TestAccBuildConfigurationIdWithParent = `
-resource "teamcity_project" "parent" {
-	name = "parent"
-}
-
-resource "teamcity_project" "child" {
-	name = "child"
-	parent_id = "${teamcity_project.parent.id}"
+const TestAccBuildConfigPausedTemplate = `
+resource "teamcity_project" "build_config_project_test" {
+  name = "build_config_project_test"
 }
 
-resource teamcity_build_config "build_config" {
-	name = "build config"
-	project_id = "${teamcity_project.child.id}"
+resource "teamcity_build_config" "build_configuration_test" {
+	name = "build config template test"
+	project_id = "${teamcity_project.build_config_project_test.id}"
+	is_template = true
+	paused = true
 }
 `
@@ -1,21 +1,23 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
 	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceBuildTriggerBuildFinish() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceBuildTriggerBuildFinishCreate,
-		Read:   resourceBuildTriggerBuildFinishRead,
-		Delete: resourceBuildTriggerBuildFinishDelete,
+		CreateContext: resourceBuildTriggerBuildFinishCreate,
+		ReadContext:   resourceBuildTriggerBuildFinishRead,
+		DeleteContext: resourceBuildTriggerBuildFinishDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -44,30 +46,32 @@ func resourceBuildTriggerBuildFinish() *schema.Resource {
 	}
 }
 
-func resourceBuildTriggerBuildFinishCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceBuildTriggerBuildFinishCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var buildConfigID, triggerBuildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
 	if v, ok := d.GetOk("source_build_config_id"); ok {
 		triggerBuildConfigID = v.(string)
 	}
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 	// validates the Trigger Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(triggerBuildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", triggerBuildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(triggerBuildConfigID); err != nil {
+		return attributeErrorf("source_build_config_id", "invalid source_build_config_id '%s' - Build configuration does not exist", triggerBuildConfigID)
 	}
 
 	ts := client.TriggerService(buildConfigID)
 	opt := api.NewTriggerBuildFinishOptions(false, nil)
 	dt, err := api.NewTriggerBuildFinish(triggerBuildConfigID, opt)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if v, ok := d.GetOk("after_successful_only"); ok {
@@ -83,48 +87,56 @@ func resourceBuildTriggerBuildFinishCreate(d *schema.ResourceData, meta interfac
 	out, err := ts.AddTrigger(dt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID())
 
-	return resourceBuildTriggerBuildFinishRead(d, meta)
+	return resourceBuildTriggerBuildFinishRead(ctx, d, meta)
 }
 
-func resourceBuildTriggerBuildFinishRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerBuildFinishRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx).TriggerService(d.Get("build_config_id").(string))
 
 	ret, err := getTrigger(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Build finish trigger '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 	dt, ok := ret.(*api.TriggerBuildFinish)
 	if !ok {
-		return fmt.Errorf("invalid trigger type when reading build_trigger_build_finish resource")
+		return diag.Errorf("invalid trigger type when reading build_trigger_build_finish resource")
 	}
 	if err := d.Set("build_config_id", dt.BuildTypeID()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("source_build_config_id", dt.SourceBuildID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	log.Printf("[INFO] READ: BranchFilter: %s, State: %s", dt.Options.BranchFilter, d.Get("branch_filter"))
 	if err := d.Set("branch_filter", flattenStringSlice(dt.Options.BranchFilter)); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if dt.Options.AfterSuccessfulBuildOnly {
-		if err := d.Set("after_sucessful_only", dt.Options.AfterSuccessfulBuildOnly); err != nil {
-			return err
+		if err := d.Set("after_successful_only", dt.Options.AfterSuccessfulBuildOnly); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
 	return nil
 }
 
-func resourceBuildTriggerBuildFinishDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	ts := client.TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerBuildFinishDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	ts := client.TriggerService(buildConfigID)
 
-	return ts.Delete(d.Id())
+	return diag.FromErr(ts.Delete(d.Id()))
 }
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccTeamcityBuildTriggerBuildFinish_Basic(t *testing.T) {
@@ -30,6 +30,14 @@ func TestAccTeamcityBuildTriggerBuildFinish_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resName, "branch_filter.1", "feature"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+				// go-teamcity reads the option back from "afterSucessfulBuildOnly", so it can't be imported
+				ImportStateVerifyIgnore: []string{"after_successful_only"},
+			},
 		},
 	})
 }
@@ -1,21 +1,24 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceBuildTriggerSchedule() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceBuildTriggerScheduleCreate,
-		Read:   resourceBuildTriggerScheduleRead,
-		Delete: resourceBuildTriggerScheduleDelete,
+		CreateContext: resourceBuildTriggerScheduleCreate,
+		ReadContext:   resourceBuildTriggerScheduleRead,
+		DeleteContext: resourceBuildTriggerScheduleDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -129,16 +132,19 @@ func resourceBuildTriggerSchedule() *schema.Resource {
 	}
 }
 
-func resourceBuildTriggerScheduleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceBuildTriggerScheduleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var buildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
 	ts := client.TriggerService(buildConfigID)
@@ -152,75 +158,83 @@ func resourceBuildTriggerScheduleCreate(d *schema.ResourceData, meta interface{}
 
 	opt, err := expandTriggerScheduleOptions(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	dt, err := api.NewTriggerSchedule(schedule, buildConfigID, weekday, uint(hour), uint(minute), timezone, rules, opt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	out, err := ts.AddTrigger(dt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID())
 
-	return resourceBuildTriggerScheduleRead(d, meta)
+	return resourceBuildTriggerScheduleRead(ctx, d, meta)
 }
 
-func resourceBuildTriggerScheduleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerScheduleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx).TriggerService(d.Get("build_config_id").(string))
 
 	ret, err := getTrigger(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Schedule trigger '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 	dt, ok := ret.(*api.TriggerSchedule)
 	if !ok {
-		return fmt.Errorf("invalid trigger type when reading build_trigger_schedule resource")
+		return diag.Errorf("invalid trigger type when reading build_trigger_schedule resource")
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("schedule", dt.SchedulingPolicy); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("hour", dt.Hour); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("minute", dt.Minute); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("timezone", dt.Timezone); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("rules", flattenStringSlice(dt.Rules)); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if dt.SchedulingPolicy == api.TriggerSchedulingWeekly {
 		if err := d.Set("weekday", dt.Weekday.String()); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	flatOpt := flattenTriggerScheduleOptions(dt.Options)
 	for k, v := range flatOpt {
 		if err := d.Set(k, v); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	return nil
 }
 
-func resourceBuildTriggerScheduleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	ts := client.TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerScheduleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	ts := client.TriggerService(buildConfigID)
 
-	return ts.Delete(d.Id())
+	return diag.FromErr(ts.Delete(d.Id()))
 }
 
 func expandTriggerScheduleOptions(d *schema.ResourceData) (*api.TriggerScheduleOptions, error) {
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccTeamcityBuildTriggerSchedule_Daily(t *testing.T) {
@@ -36,6 +36,12 @@ func TestAccTeamcityBuildTriggerSchedule_Daily(t *testing.T) {
 					resource.TestCheckResourceAttr(resName, "rules.1", "-:*.md"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
 		},
 	})
 }
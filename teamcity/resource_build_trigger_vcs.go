@@ -1,20 +1,23 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceBuildTriggerVcs() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceBuildTriggerVcsCreate,
-		Read:   resourceBuildTriggerVcsRead,
-		Delete: resourceBuildTriggerVcsDelete,
+		CreateContext: resourceBuildTriggerVcsCreate,
+		ReadContext:   resourceBuildTriggerVcsRead,
+		DeleteContext: resourceBuildTriggerVcsDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -38,17 +41,20 @@ func resourceBuildTriggerVcs() *schema.Resource {
 	}
 }
 
-func resourceBuildTriggerVcsCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceBuildTriggerVcsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var buildConfigID string
 	var err error
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
 	ts := client.TriggerService(buildConfigID)
@@ -56,10 +62,10 @@ func resourceBuildTriggerVcsCreate(d *schema.ResourceData, meta interface{}) err
 	if v, ok := d.GetOk("rules"); ok {
 		dt, err = api.NewTriggerVcs(expandStringSlice(v.([]interface{})), []string{})
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	} else {
-		return fmt.Errorf("error getting required property 'rules' for vcs trigger")
+		return diag.Errorf("error getting required property 'rules' for vcs trigger")
 	}
 
 	if v, ok := d.GetOk("branch_filter"); ok {
@@ -69,50 +75,58 @@ func resourceBuildTriggerVcsCreate(d *schema.ResourceData, meta interface{}) err
 	out, err := ts.AddTrigger(dt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID())
 
-	return resourceBuildTriggerVcsRead(d, meta)
+	return resourceBuildTriggerVcsRead(ctx, d, meta)
 }
 
-func resourceBuildTriggerVcsRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerVcsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx).TriggerService(d.Get("build_config_id").(string))
 
 	ret, err := getTrigger(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] VCS trigger '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 	dt, ok := ret.(*api.TriggerVcs)
 	if !ok {
-		return fmt.Errorf("invalid trigger type when reading build_trigger_vcs resource")
+		return diag.Errorf("invalid trigger type when reading build_trigger_vcs resource")
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if len(dt.Rules) > 0 {
 		if err := d.Set("rules", dt.Rules); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if len(dt.BranchFilter) > 0 {
 		if err := d.Set("branch_filter", dt.BranchFilter); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	return nil
 }
 
-func resourceBuildTriggerVcsDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	ts := client.TriggerService(d.Get("build_config_id").(string))
+func resourceBuildTriggerVcsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	ts := client.TriggerService(buildConfigID)
 
-	return ts.Delete(d.Id())
+	return diag.FromErr(ts.Delete(d.Id()))
 }
 
 func getTrigger(c *api.TriggerService, id string) (api.Trigger, error) {
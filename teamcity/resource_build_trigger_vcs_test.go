@@ -7,8 +7,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcityBuildTriggerVcs_Basic(t *testing.T) {
@@ -30,6 +31,35 @@ func TestAccTeamcityBuildTriggerVcs_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resName, "branch_filter.0", "+:pull/*"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
+		},
+	})
+}
+
+func TestAccTeamcityBuildTriggerVcs_Disappears(t *testing.T) {
+	resName := "teamcity_build_trigger_vcs.test"
+	var out api.Trigger
+	var bc api.BuildType
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityBuildTriggerDestroy(&bc.ID, "teamcity_build_trigger_vcs"),
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: TestAccBuildTriggerVcsBasic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists("teamcity_build_config.config", &bc),
+					testAccCheckTeamcityBuildTriggerExists(resName, &bc.ID, &out, true),
+					testAccCheckTeamcityBuildTriggerDisappears(&bc.ID, &out),
+				),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
@@ -66,7 +96,7 @@ func TestAccTeamcityBuildTriggerVcs_Update(t *testing.T) {
 
 func testAccCheckTeamcityBuildTriggerDestroy(bt *string, resourceType string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return buildTriggerDestroyHelper(s, bt, client, resourceType)
 	}
 }
@@ -92,9 +122,17 @@ func buildTriggerDestroyHelper(s *terraform.State, bt *string, client *api.Clien
 	return nil
 }
 
+// testAccCheckTeamcityBuildTriggerDisappears deletes the trigger outside of Terraform
+func testAccCheckTeamcityBuildTriggerDisappears(buildTypeId *string, t *api.Trigger) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
+		return client.TriggerService(*buildTypeId).Delete((*t).ID())
+	}
+}
+
 func testAccCheckTeamcityBuildTriggerRemoved(buildTypeId *string, t *api.Trigger) resource.TestCheckFunc {
 	return func(S *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 
 		_, err := client.TriggerService(*buildTypeId).GetByID((*t).ID())
 		if err != nil {
@@ -109,7 +147,7 @@ func testAccCheckTeamcityBuildTriggerRemoved(buildTypeId *string, t *api.Trigger
 
 func testAccCheckTeamcityBuildTriggerExists(n string, bt *string, t *api.Trigger, exists bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 
 		found, err := teamcityBuildTriggerExistsHelper(n, bt, s, client, t)
 		if !exists {
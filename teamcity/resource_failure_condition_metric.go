@@ -0,0 +1,222 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Metric-based failure conditions are build features of this type
+const featureTypeFailureOnMetric = "BuildFailureOnMetric"
+
+// failureMetricKeys maps the metric names supported by the resource to TeamCity statistic keys.
+// Other names are sent as is, for custom statistics.
+var failureMetricKeys = map[string]string{
+	"artifact_size":       "buildArtifactsTotalSize",
+	"build_duration":      "BuildDurationNetTime",
+	"test_count":          "buildTestCount",
+	"failed_test_count":   "buildFailedTestCount",
+	"ignored_test_count":  "buildIgnoredTestCount",
+	"inspection_errors":   "InspectionStatsE",
+	"inspection_warnings": "InspectionStatsW",
+	"duplicates":          "DuplicatorStats",
+	"line_coverage":       "CodeCoverageL",
+	"method_coverage":     "CodeCoverageM",
+	"class_coverage":      "CodeCoverageC",
+	"block_coverage":      "CodeCoverageB",
+	"statement_coverage":  "CodeCoverageS",
+}
+
+var failureMetricUnits = map[string]string{
+	"default": "metricUnitsDefault",
+	"percent": "metricUnitsPercent",
+}
+
+var failureMetricAnchors = map[string]string{
+	"last_successful": "lastSuccessful",
+	"last_pinned":     "lastPinned",
+	"last_finished":   "lastFinished",
+}
+
+func resourceFailureConditionMetric() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFailureConditionMetricCreate,
+		ReadContext:   resourceFailureConditionMetricRead,
+		DeleteContext: resourceFailureConditionMetricDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceBuildConfigChildImport,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"build_config_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"metric": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"comparison": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"more", "less"}, false),
+			},
+			"threshold": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"units": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "default",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"default", "percent"}, false),
+			},
+			"compare_to": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "last_successful",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"last_successful", "last_pinned", "last_finished", "value"}, false),
+			},
+			"stop_build": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceFailureConditionMetricCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
+	// validates the Build Configuration exists
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	}
+
+	if d.Get("units").(string) == "percent" && d.Get("compare_to").(string) == "value" {
+		return attributeErrorf("units", "percent units require comparing to a build, compare_to can't be 'value'")
+	}
+
+	out, err := meta.(*Meta).rest(ctx).addBuildFeature(buildConfigID, expandFailureConditionMetric(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(out.ID)
+
+	return resourceFailureConditionMetricRead(ctx, d, meta)
+}
+
+func resourceFailureConditionMetricRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	buildConfigID := d.Get("build_config_id").(string)
+	dt, err := meta.(*Meta).rest(ctx).getBuildFeature(buildConfigID, d.Id())
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Metric failure condition '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if dt.Type != featureTypeFailureOnMetric {
+		return diag.FromErr(fmt.Errorf("build feature '%s' of build configuration '%s' is a '%s' feature, not a metric failure condition", d.Id(), buildConfigID, dt.Type))
+	}
+
+	for k, v := range flattenFailureConditionMetric(dt) {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
+
+func resourceFailureConditionMetricDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
+	err := meta.(*Meta).rest(ctx).deleteBuildFeature(buildConfigID, d.Id())
+	if err != nil && isNotFoundError(err) {
+		return nil
+	}
+	return diag.FromErr(err)
+}
+
+func expandFailureConditionMetric(d *schema.ResourceData) *buildFeature {
+	metric := d.Get("metric").(string)
+	if key, ok := failureMetricKeys[metric]; ok {
+		metric = key
+	}
+
+	props := api.NewPropertiesEmpty()
+	props.AddOrReplaceValue("metricKey", metric)
+	props.AddOrReplaceValue("metricThreshold", strconv.Itoa(d.Get("threshold").(int)))
+	props.AddOrReplaceValue("metricUnits", failureMetricUnits[d.Get("units").(string)])
+	props.AddOrReplaceValue("moreOrLess", d.Get("comparison").(string))
+	if anchor, ok := failureMetricAnchors[d.Get("compare_to").(string)]; ok {
+		props.AddOrReplaceValue("withBuildAnchor", "true")
+		props.AddOrReplaceValue("anchorBuild", anchor)
+	} else {
+		props.AddOrReplaceValue("withBuildAnchor", "false")
+	}
+	if d.Get("stop_build").(bool) {
+		props.AddOrReplaceValue("stopBuildOnFailure", "true")
+	}
+
+	return &buildFeature{
+		Type:       featureTypeFailureOnMetric,
+		Properties: props,
+	}
+}
+
+func flattenFailureConditionMetric(f *buildFeature) map[string]interface{} {
+	metric := f.property("metricKey")
+	for k, v := range failureMetricKeys {
+		if v == metric {
+			metric = k
+		}
+	}
+	threshold, _ := strconv.Atoi(f.property("metricThreshold"))
+
+	units := "default"
+	for k, v := range failureMetricUnits {
+		if v == f.property("metricUnits") {
+			units = k
+		}
+	}
+	compareTo := "value"
+	if f.property("withBuildAnchor") == "true" {
+		compareTo = "last_successful"
+		for k, v := range failureMetricAnchors {
+			if v == f.property("anchorBuild") {
+				compareTo = k
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"metric":     metric,
+		"comparison": f.property("moreOrLess"),
+		"threshold":  threshold,
+		"units":      units,
+		"compare_to": compareTo,
+		"stop_build": f.property("stopBuildOnFailure") == "true",
+	}
+}
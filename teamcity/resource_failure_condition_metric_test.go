@@ -0,0 +1,106 @@
+package teamcity_test
+
+import (
+	"regexp"
+	"testing"
+
+	api "github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTeamcityFailureConditionMetric_Basic(t *testing.T) {
+	resName := "teamcity_failure_condition_metric.test"
+	var bc api.BuildType
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: TestAccFailureConditionMetricBasic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildConfigExists("teamcity_build_config.config", &bc),
+					resource.TestCheckResourceAttrPtr(resName, "build_config_id", &bc.ID),
+					resource.TestCheckResourceAttr(resName, "metric", "artifact_size"),
+					resource.TestCheckResourceAttr(resName, "comparison", "less"),
+					resource.TestCheckResourceAttr(resName, "threshold", "20"),
+					resource.TestCheckResourceAttr(resName, "units", "percent"),
+					resource.TestCheckResourceAttr(resName, "compare_to", "last_successful"),
+					resource.TestCheckResourceAttr(resName, "stop_build", "false"),
+					resource.TestCheckResourceAttr("teamcity_failure_condition_metric.custom", "metric", "myCustomStatistic"),
+					resource.TestCheckResourceAttr("teamcity_failure_condition_metric.custom", "compare_to", "value"),
+					resource.TestCheckResourceAttr("teamcity_failure_condition_metric.custom", "stop_build", "true"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
+		},
+	})
+}
+
+func TestAccTeamcityFailureConditionMetric_PercentOfValue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildConfigDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      TestAccFailureConditionMetricPercentOfValue,
+				ExpectError: regexp.MustCompile("percent units require comparing to a build"),
+			},
+		},
+	})
+}
+
+const TestAccFailureConditionMetricBasic = `
+resource "teamcity_project" "failure_condition_project_test" {
+  name = "Failure Condition Project"
+}
+
+resource "teamcity_build_config" "config" {
+	name = "BuildConfig"
+	project_id = "${teamcity_project.failure_condition_project_test.id}"
+}
+
+resource "teamcity_failure_condition_metric" "test" {
+	build_config_id = "${teamcity_build_config.config.id}"
+	metric = "artifact_size"
+	comparison = "less"
+	threshold = 20
+	units = "percent"
+}
+
+resource "teamcity_failure_condition_metric" "custom" {
+	build_config_id = "${teamcity_build_config.config.id}"
+	metric = "myCustomStatistic"
+	comparison = "more"
+	threshold = 100
+	compare_to = "value"
+	stop_build = true
+}
+`
+
+const TestAccFailureConditionMetricPercentOfValue = `
+resource "teamcity_project" "failure_condition_project_test" {
+  name = "Failure Condition Project"
+}
+
+resource "teamcity_build_config" "config" {
+	name = "BuildConfig"
+	project_id = "${teamcity_project.failure_condition_project_test.id}"
+}
+
+resource "teamcity_failure_condition_metric" "test" {
+	build_config_id = "${teamcity_build_config.config.id}"
+	metric = "test_count"
+	comparison = "less"
+	threshold = 10
+	units = "percent"
+	compare_to = "value"
+}
+`
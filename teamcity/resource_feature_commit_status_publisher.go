@@ -2,23 +2,26 @@ package teamcity
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"log"
 	"strings"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceFeatureCommitStatusPublisher() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceFeatureCommitStatusPublisherCreate,
-		Read:   resourceFeatureCommitStatusPublisherRead,
-		Delete: resourceFeatureCommitStatusPublisherDelete,
+		CreateContext: resourceFeatureCommitStatusPublisherCreate,
+		ReadContext:   resourceFeatureCommitStatusPublisherRead,
+		DeleteContext: resourceFeatureCommitStatusPublisherDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -78,17 +81,19 @@ func resourceFeatureCommitStatusPublisher() *schema.Resource {
 	}
 }
 
-func resourceFeatureCommitStatusPublisherCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceFeatureCommitStatusPublisherCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var buildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
 
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
 	srv := client.BuildFeatureService(buildConfigID)
@@ -97,34 +102,39 @@ func resourceFeatureCommitStatusPublisherCreate(d *schema.ResourceData, meta int
 
 	dt, err := buildGithubCommitStatusPublisher(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	out, err := srv.Create(dt)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID())
 
-	return resourceFeatureCommitStatusPublisherRead(d, meta)
+	return resourceFeatureCommitStatusPublisherRead(ctx, d, meta)
 }
 
-func resourceFeatureCommitStatusPublisherRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).BuildFeatureService(d.Get("build_config_id").(string))
+func resourceFeatureCommitStatusPublisherRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx).BuildFeatureService(d.Get("build_config_id").(string))
 
 	dt, err := getBuildFeatureCommitPublisher(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Commit status publisher '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	//TODO: Implement other publishers
 	if err := d.Set("publisher", "github"); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	opt := dt.Options.(*api.StatusPublisherGithubOptions)
@@ -139,14 +149,17 @@ func resourceFeatureCommitStatusPublisherRead(d *schema.ResourceData, meta inter
 	}
 
 	optsToSave = append(optsToSave, m)
-	return d.Set("github", optsToSave)
+	return diag.FromErr(d.Set("github", optsToSave))
 }
 
-func resourceFeatureCommitStatusPublisherDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	svr := client.BuildFeatureService(d.Get("build_config_id").(string))
+func resourceFeatureCommitStatusPublisherDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	svr := client.BuildFeatureService(buildConfigID)
 
-	return svr.Delete(d.Id())
+	return diag.FromErr(svr.Delete(d.Id()))
 }
 
 func buildGithubCommitStatusPublisher(d *schema.ResourceData) (api.BuildFeature, error) {
@@ -185,5 +198,5 @@ func githubPublisherOptionsHash(v interface{}) int {
 		buf.WriteString(fmt.Sprintf("%s-", v.(string)))
 	}
 
-	return hashcode.String(buf.String())
+	return schema.HashString(buf.String())
 }
@@ -6,8 +6,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcityFeatureCommitStatusPublisher_Github(t *testing.T) {
@@ -26,11 +27,22 @@ func TestAccTeamcityFeatureCommitStatusPublisher_Github(t *testing.T) {
 					testAccCheckBuildConfigExists("teamcity_build_config.config", &bc),
 					testAccCheckBuildFeatureExists(resName, &bc.ID, &out),
 					resource.TestCheckResourceAttr(resName, "publisher", "github"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.auth_type", "password"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.host", "https://api.github.com"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.username", "bob"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "github.*", map[string]string{
+						"auth_type": "password",
+						"host":      "https://api.github.com",
+						"username":  "bob",
+					}),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+				ImportStateVerifyIgnore: []string{
+					"github.0.password", // not returned by the server
+				},
+			},
 		},
 	})
 }
@@ -51,9 +63,11 @@ func TestAccTeamcityFeatureCommitStatusPublisher_GithubUpdate(t *testing.T) {
 					testAccCheckBuildConfigExists("teamcity_build_config.config", &bc),
 					testAccCheckBuildFeatureExists(resName, &bc.ID, &out),
 					resource.TestCheckResourceAttr(resName, "publisher", "github"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.auth_type", "password"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.host", "https://api.github.com"),
-					resource.TestCheckResourceAttr(resName, "github.3735060251.username", "bob"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "github.*", map[string]string{
+						"auth_type": "password",
+						"host":      "https://api.github.com",
+						"username":  "bob",
+					}),
 				),
 			},
 			resource.TestStep{
@@ -62,8 +76,10 @@ func TestAccTeamcityFeatureCommitStatusPublisher_GithubUpdate(t *testing.T) {
 					testAccCheckBuildConfigExists("teamcity_build_config.config", &bc),
 					testAccCheckBuildFeatureExists(resName, &bc.ID, &out),
 					resource.TestCheckResourceAttr(resName, "publisher", "github"),
-					resource.TestCheckResourceAttr(resName, "github.3764292600.host", "https://api.github.com/v3"),
-					resource.TestCheckResourceAttr(resName, "github.3764292600.username", "bob_updated"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "github.*", map[string]string{
+						"host":     "https://api.github.com/v3",
+						"username": "bob_updated",
+					}),
 				),
 			},
 		},
@@ -72,7 +88,7 @@ func TestAccTeamcityFeatureCommitStatusPublisher_GithubUpdate(t *testing.T) {
 
 func testAccCheckBuildFeatureDestroy(bt *string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return buildFeatureDestroyHelper(s, bt, client)
 	}
 }
@@ -100,7 +116,7 @@ func buildFeatureDestroyHelper(s *terraform.State, bt *string, client *api.Clien
 
 func testAccCheckBuildFeatureExists(n string, bt *string, out *api.BuildFeature) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return teamcityBuildFeatureExistsHelper(n, bt, s, client, out)
 	}
 }
@@ -1,24 +1,27 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"hash/crc32"
 	"regexp"
 	"strings"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceGroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceGroupCreate,
-		Read:   resourceGroupRead,
-		Update: resourceGroupUpdate,
-		Delete: resourceGroupDelete,
+		CreateContext: resourceGroupCreate,
+		ReadContext:   resourceGroupRead,
+		UpdateContext: resourceGroupUpdate,
+		DeleteContext: resourceGroupDelete,
 		Importer: &schema.ResourceImporter{
-			State: resourceGroupImport,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: updatableResourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"key": {
@@ -46,8 +49,8 @@ func resourceGroup() *schema.Resource {
 	}
 }
 
-func resourceGroupCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var key, name, description string
 	var importIfExists bool
 
@@ -71,19 +74,19 @@ func resourceGroupCreate(d *schema.ResourceData, meta interface{}) error {
 		generateKey, err := generateKey(name)
 
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		key = *generateKey
 	}
 
 	newGroup, err := api.NewGroup(key, name, description)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	created, err := client.Groups.Create(newGroup)
 	if err != nil && !(importIfExists && strings.Contains(err.Error(), "group with the same key already exists")) {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if created != nil {
@@ -93,7 +96,7 @@ func resourceGroupCreate(d *schema.ResourceData, meta interface{}) error {
 		d.SetId(key)
 	}
 
-	return resourceGroupRead(d, meta)
+	return resourceGroupRead(ctx, d, meta)
 }
 
 func generateKey(name string) (*string, error) {
@@ -108,45 +111,38 @@ func generateKey(name string) (*string, error) {
 	return &generatedKey, nil
 }
 
-func resourceGroupRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	dt, err := client.Groups.GetByKey(d.Id())
 	if err != nil {
-		if strings.Contains(err.Error(), "404") {
+		if isNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("key", dt.Key); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("name", dt.Name); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("description", dt.Description); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	return nil
 }
 
-func resourceGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// The only attribute in the schema that does not have "ForceNew: true" is "import_if_exists",
 	// so we are not actually updating any groups in TeamCity, we just need to read and return.
-	return resourceGroupRead(d, meta)
+	return resourceGroupRead(ctx, d, meta)
 }
 
-func resourceGroupDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
-	return client.Groups.Delete(d.Id())
-}
-
-func resourceGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	if err := resourceGroupRead(d, meta); err != nil {
-		return nil, err
-	}
-	return []*schema.ResourceData{d}, nil
+	return diag.FromErr(client.Groups.Delete(d.Id()))
 }
@@ -1,21 +1,24 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceGroupRoleAssignment() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceGroupRoleAssignmentCreate,
-		Read:   resourceGroupRoleAssignmentRead,
-		Delete: resourceGroupRoleAssignmentDelete,
+		CreateContext: resourceGroupRoleAssignmentCreate,
+		ReadContext:   resourceGroupRoleAssignmentRead,
+		DeleteContext: resourceGroupRoleAssignmentDelete,
 		Importer: &schema.ResourceImporter{
-			State: resourceGroupRoleAssignmentImport,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"group_key": {
@@ -37,8 +40,8 @@ func resourceGroupRoleAssignment() *schema.Resource {
 	}
 }
 
-func resourceGroupRoleAssignmentCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupRoleAssignmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var groupKey, roleID, projectID string
 
 	if v, ok := d.GetOk("group_key"); ok {
@@ -55,65 +58,58 @@ func resourceGroupRoleAssignmentCreate(d *schema.ResourceData, meta interface{})
 
 	newGroupRoleAssignment, err := api.NewGroupRoleAssignment(groupKey, roleID, projectID)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	_, err = client.RoleAssignments.AssignToGroup(newGroupRoleAssignment)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.MarkNewResource()
 	d.SetId(fmt.Sprintf("%s/%s/%s", groupKey, roleID, projectID))
 
-	return resourceGroupRoleAssignmentRead(d, meta)
+	return resourceGroupRoleAssignmentRead(ctx, d, meta)
 }
 
-func resourceGroupRoleAssignmentRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupRoleAssignmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	newGroupRoleAssignment, err := createGroupRoleAssignmentFromResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	dt, err := client.RoleAssignments.GetForGroup(newGroupRoleAssignment)
 	if err != nil {
-		if strings.Contains(err.Error(), "404") {
+		if isNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("group_key", newGroupRoleAssignment.GroupKey); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("role_id", dt.RoleID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("project_id", dt.Scope); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	return nil
 }
 
-func resourceGroupRoleAssignmentDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceGroupRoleAssignmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	newGroupRoleAssignment, err := createGroupRoleAssignmentFromResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return client.RoleAssignments.UnassignFromGroup(newGroupRoleAssignment)
-}
-
-func resourceGroupRoleAssignmentImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	if err := resourceGroupRoleAssignmentRead(d, meta); err != nil {
-		return nil, err
-	}
-	return []*schema.ResourceData{d}, nil
+	return diag.FromErr(client.RoleAssignments.UnassignFromGroup(newGroupRoleAssignment))
 }
 
 func createGroupRoleAssignmentFromResourceData(d *schema.ResourceData) (*api.GroupRoleAssignment, error) {
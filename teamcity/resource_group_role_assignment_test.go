@@ -5,10 +5,11 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
 )
 
 func TestAccGroupRoleAssignmentAssign_SysAdmin(t *testing.T) {
@@ -58,7 +59,7 @@ func TestAccGroupRoleAssignmentAssign_ProjDev(t *testing.T) {
 }
 
 func testAccCheckGroupRoleAssignmentDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	return groupRoleAssignmentDestroyHelper(s, client)
 }
 
@@ -85,7 +86,7 @@ func groupRoleAssignmentDestroyHelper(s *terraform.State, client *api.Client) er
 
 func testAccCheckGroupRoleAssignmentExists(n string, out *api.RoleAssignmentReference) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return groupRoleAssignmentExistsHelper(n, s, client, out)
 	}
 }
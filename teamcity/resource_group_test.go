@@ -6,13 +6,14 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"hash/crc32"
 	"regexp"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
 )
 
 func TestAccGroup_Create(t *testing.T) {
@@ -128,7 +129,6 @@ func createGroup(testGroupKey string, groupName string, groupDescription string)
 	if err == nil {
 		newGroup, _ := api.NewGroup(testGroupKey, groupName, groupDescription)
 		client.Groups.Create(newGroup)
-		fmt.Sprintf("Made new group: %s", newGroup.Key)
 	}
 }
 
@@ -139,7 +139,7 @@ func generateKey(name string) string {
 }
 
 func testAccCheckGroupDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	return buildGroupDestroyHelper(s, client)
 }
 
@@ -165,7 +165,7 @@ func buildGroupDestroyHelper(s *terraform.State, client *api.Client) error {
 
 func testAccCheckGroupExists(n string, out *api.Group) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return groupExistsHelper(n, s, client, out)
 	}
 }
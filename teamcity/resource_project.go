@@ -1,22 +1,27 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceProject() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceProjectCreate,
-		Read:   resourceProjectRead,
-		Update: resourceProjectUpdate,
-		Delete: resourceProjectDelete,
+		CreateContext: resourceProjectCreate,
+		ReadContext:   resourceProjectRead,
+		UpdateContext: resourceProjectUpdate,
+		DeleteContext: resourceProjectDelete,
 		Importer: &schema.ResourceImporter{
-			State: resourceProjectImport,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: updatableResourceTimeouts(),
+
+		CustomizeDiff: effectiveParamsDiff("env_params", "config_params", "sys_params", "parameter", "password_params", "parent_id"),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -44,12 +49,15 @@ func resourceProject() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"parameter":        parameterSchema(),
+			"password_params":  passwordParamsSchema(),
+			"effective_params": effectiveParamsSchema(),
 		},
 	}
 }
 
-func resourceProjectCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceProjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	var name, parentID string
 
 	if v, ok := d.GetOk("name"); ok {
@@ -64,25 +72,25 @@ func resourceProjectCreate(d *schema.ResourceData, meta interface{}) error {
 
 	newProj, err := api.NewProject(name, "", parentID)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	created, err := client.Projects.Create(newProj)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.MarkNewResource()
 	d.SetId(created.ID)
 
-	return resourceProjectUpdate(d, client)
+	return resourceProjectUpdate(ctx, d, meta)
 }
 
-func resourceProjectUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceProjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	dt, err := client.Projects.GetByID(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if d.HasChange("description") {
@@ -97,33 +105,55 @@ func resourceProjectUpdate(d *schema.ResourceData, meta interface{}) error {
 		dt.SetParentProject(parentId)
 	}
 
-	dt.Parameters, err = expandParameterCollection(d)
+	specs, err := expandParameterSpecs(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	passwords, err := expandPasswordParams(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
+	// The api client writes parameters without their type specification, so they are all written with the rest client instead
+	dt.Parameters = api.NewParametersEmpty()
 	_, err = client.Projects.Update(dt)
 	if err != nil {
-		return nil
+		return diag.FromErr(err)
+	}
+
+	if d.HasChanges("env_params", "config_params", "sys_params", "parameter", "password_params") {
+		params, err := expandParameterList(d, specs, passwords)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := meta.(*Meta).rest(ctx).replaceParameters(projectParametersPath(d.Id()), params); err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	return resourceProjectRead(d, meta)
+
+	return resourceProjectRead(ctx, d, meta)
 }
 
-func resourceProjectRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceProjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 
 	dt, err := getProject(client, d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Project '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 	if err := d.Set("name", dt.Name); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("description", dt.Description); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("parent_id", dt.ParentProject.ID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.Set("name", dt.Name)
@@ -134,22 +164,19 @@ func resourceProjectRead(d *schema.ResourceData, meta interface{}) error {
 	}
 	d.Set("parent_id", parentProjectId)
 
-	return flattenParameterCollection(d, dt.Parameters)
+	params, err := meta.(*Meta).rest(ctx).getParameters(projectParametersPath(d.Id()))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return diag.FromErr(flattenParameterCollection(d, params))
 }
 
-func resourceProjectDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceProjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	log.Print(fmt.Sprintf("[DEBUG]: resourceProjectDelete - Destroying project %v", d.Id()))
 	err := client.Projects.Delete(d.Id())
 	log.Print(fmt.Sprintf("[INFO]: resourceProjectDelete - Destroyed project %v", d.Id()))
-	return err
-}
-
-func resourceProjectImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	if err := resourceProjectRead(d, meta); err != nil {
-		return nil, err
-	}
-	return []*schema.ResourceData{d}, nil
+	return diag.FromErr(err)
 }
 
 func getProject(c *api.Client, id string) (*api.Project, error) {
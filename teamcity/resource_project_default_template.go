@@ -0,0 +1,115 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceProjectDefaultTemplate() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceProjectDefaultTemplateCreate,
+		ReadContext:   resourceProjectDefaultTemplateRead,
+		UpdateContext: resourceProjectDefaultTemplateUpdate,
+		DeleteContext: resourceProjectDefaultTemplateDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Timeouts: updatableResourceTimeouts(),
+
+		// The template is validated when planning if it already exists, otherwise when it's set
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if !diff.HasChange("template_id") || !diff.NewValueKnown("template_id") {
+				return nil
+			}
+			return validateDefaultTemplate(meta.(*Meta).rest(ctx), diff.Get("template_id").(string))
+		},
+
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"template_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func resourceProjectDefaultTemplateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	projectID := d.Get("project_id").(string)
+	if err := setDefaultTemplate(ctx, d, meta, projectID); err != nil {
+		return err
+	}
+
+	d.SetId(projectID)
+	return resourceProjectDefaultTemplateRead(ctx, d, meta)
+}
+
+func resourceProjectDefaultTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := setDefaultTemplate(ctx, d, meta, d.Id()); err != nil {
+		return err
+	}
+	return resourceProjectDefaultTemplateRead(ctx, d, meta)
+}
+
+func resourceProjectDefaultTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	templateID, err := meta.(*Meta).rest(ctx).getProjectDefaultTemplate(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// TeamCity answers not found for a deleted project too
+	if templateID == "" {
+		log.Printf("[DEBUG] Default template of project '%s' not found - removing from state!", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err := d.Set("project_id", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("template_id", templateID); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceProjectDefaultTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := meta.(*Meta).rest(ctx).setProjectDefaultTemplate(d.Id(), ""); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func setDefaultTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}, projectID string) diag.Diagnostics {
+	rest := meta.(*Meta).rest(ctx)
+	templateID := d.Get("template_id").(string)
+	if err := validateDefaultTemplate(rest, templateID); err != nil {
+		return attributeErrorf("template_id", "%s", err)
+	}
+	if err := rest.setProjectDefaultTemplate(projectID, templateID); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// validateDefaultTemplate returns an error if the build configuration doesn't exist or isn't a template
+func validateDefaultTemplate(rest *restClient, templateID string) error {
+	template, err := rest.getBuildType(templateID)
+	if err != nil {
+		if isNotFoundError(err) {
+			return fmt.Errorf("invalid template_id '%s' - Build configuration does not exist", templateID)
+		}
+		return err
+	}
+	if !template.IsTemplate {
+		return fmt.Errorf("invalid template_id '%s' - Build configuration is not a template, is_template = false", templateID)
+	}
+	return nil
+}
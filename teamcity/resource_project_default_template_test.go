@@ -0,0 +1,74 @@
+package teamcity_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTeamcityProjectDefaultTemplate_Basic(t *testing.T) {
+	resName := "teamcity_project_default_template.default_template_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The template belongs to the project it's the default of
+				Config: fmt.Sprintf(testAccTeamcityProjectDefaultTemplate, "baseline"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resName, "project_id", "teamcity_project.default_template_test", "id"),
+					resource.TestCheckResourceAttrPair(resName, "template_id", "teamcity_build_config.baseline", "id"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:             fmt.Sprintf(testAccTeamcityProjectDefaultTemplate, "regular"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile("Build configuration is not a template"),
+			},
+			{
+				Config: fmt.Sprintf(testAccTeamcityProjectDefaultTemplate, "other"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resName, "template_id", "teamcity_build_config.other", "id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccTeamcityProjectDefaultTemplate = `
+resource "teamcity_project" "default_template_test" {
+	name = "default_template_test"
+}
+
+resource "teamcity_build_config" "baseline" {
+	name = "baseline"
+	project_id = "${teamcity_project.default_template_test.id}"
+	is_template = true
+}
+
+resource "teamcity_build_config" "other" {
+	name = "other"
+	project_id = "${teamcity_project.default_template_test.id}"
+	is_template = true
+}
+
+resource "teamcity_build_config" "regular" {
+	name = "regular"
+	project_id = "${teamcity_project.default_template_test.id}"
+}
+
+resource "teamcity_project_default_template" "default_template_test" {
+	project_id = "${teamcity_project.default_template_test.id}"
+	template_id = "${teamcity_build_config.%s.id}"
+}
+`
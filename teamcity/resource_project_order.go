@@ -0,0 +1,169 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceProjectOrder() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceProjectOrderCreate,
+		ReadContext:   resourceProjectOrderRead,
+		UpdateContext: resourceProjectOrderUpdate,
+		DeleteContext: resourceProjectOrderDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Timeouts: updatableResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"build_config_order": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"subproject_order": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceProjectOrderCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	projectID := d.Get("project_id").(string)
+	if err := setProjectOrder(ctx, d, meta, projectID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(projectID)
+	return resourceProjectOrderRead(ctx, d, meta)
+}
+
+func resourceProjectOrderUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := setProjectOrder(ctx, d, meta, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceProjectOrderRead(ctx, d, meta)
+}
+
+func resourceProjectOrderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	rest := meta.(*Meta).rest(ctx)
+
+	buildConfigs, err := rest.getBuildConfigOrder(d.Id())
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Project '%s' not found - removing order from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	subprojects, err := rest.getSubprojectOrder(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("project_id", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	// Only the relative order of the configured children is tracked, TeamCity shows the other ones after them
+	if v := d.Get("build_config_order").([]interface{}); len(v) > 0 {
+		buildConfigs = filterOrder(buildConfigs, v)
+	}
+	if err := d.Set("build_config_order", buildConfigs); err != nil {
+		return diag.FromErr(err)
+	}
+	if v := d.Get("subproject_order").([]interface{}); len(v) > 0 {
+		subprojects = filterOrder(subprojects, v)
+	}
+	if err := d.Set("subproject_order", subprojects); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceProjectOrderDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	rest := meta.(*Meta).rest(ctx)
+
+	// Resets TeamCity to its default order
+	if err := rest.setBuildConfigOrder(d.Id(), nil); err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if err := rest.setSubprojectOrder(d.Id(), nil); err != nil && !isNotFoundError(err) {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// setProjectOrder writes the configured orders, which may only reference the children of the project
+func setProjectOrder(ctx context.Context, d *schema.ResourceData, meta interface{}, projectID string) error {
+	dt, err := getProject(meta.(*Meta).client(ctx), projectID)
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("build_config_order") {
+		children := make([]interface{}, 0, len(dt.BuildTypes.Items))
+		for _, bt := range dt.BuildTypes.Items {
+			children = append(children, bt.ID)
+		}
+		ids := expandStringSlice(d.Get("build_config_order").([]interface{}))
+		if err := validateOrder(ids, children, "build configuration", projectID); err != nil {
+			return err
+		}
+		if err := meta.(*Meta).rest(ctx).setBuildConfigOrder(projectID, ids); err != nil {
+			return err
+		}
+	}
+	if d.HasChange("subproject_order") {
+		children := make([]interface{}, 0, len(dt.ChildProjects.Items))
+		for _, p := range dt.ChildProjects.Items {
+			children = append(children, p.ID)
+		}
+		ids := expandStringSlice(d.Get("subproject_order").([]interface{}))
+		if err := validateOrder(ids, children, "subproject", projectID); err != nil {
+			return err
+		}
+		if err := meta.(*Meta).rest(ctx).setSubprojectOrder(projectID, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOrder returns an error for the IDs of the order that aren't children of the project
+func validateOrder(order []string, children []interface{}, kind string, projectID string) error {
+	for _, id := range order {
+		if _, ok := sliceContainsString(children, id); !ok {
+			return fmt.Errorf("'%s' is not a %s of project '%s'", id, kind, projectID)
+		}
+	}
+	return nil
+}
+
+// filterOrder returns the IDs of order that are in ids, keeping their order
+func filterOrder(order []string, ids []interface{}) []string {
+	out := make([]string, 0, len(order))
+	for _, id := range order {
+		if _, ok := sliceContainsString(ids, id); ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package teamcity_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTeamcityProjectOrder_Basic(t *testing.T) {
+	resName := "teamcity_project_order.order_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTeamcityProjectOrder, "build_b.id, teamcity_build_config.build_a.id", "sub_b.id, teamcity_project.sub_a.id"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "project_id", "OrderTest"),
+					resource.TestCheckResourceAttr(resName, "build_config_order.#", "2"),
+					resource.TestCheckResourceAttr(resName, "build_config_order.0", "OrderTest_BuildB"),
+					resource.TestCheckResourceAttr(resName, "build_config_order.1", "OrderTest_BuildA"),
+					resource.TestCheckResourceAttr(resName, "subproject_order.#", "2"),
+					resource.TestCheckResourceAttr(resName, "subproject_order.0", "OrderTest_SubB"),
+					resource.TestCheckResourceAttr(resName, "subproject_order.1", "OrderTest_SubA"),
+				),
+			},
+			{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// Only the relative order of the configured children is tracked
+				Config: fmt.Sprintf(testAccTeamcityProjectOrder, "build_a.id, teamcity_build_config.build_b.id", "sub_a.id"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "build_config_order.0", "OrderTest_BuildA"),
+					resource.TestCheckResourceAttr(resName, "build_config_order.1", "OrderTest_BuildB"),
+					resource.TestCheckResourceAttr(resName, "subproject_order.#", "1"),
+					resource.TestCheckResourceAttr(resName, "subproject_order.0", "OrderTest_SubA"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTeamcityProjectOrder_UnknownChild(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTeamcityProjectOrderUnknownChild,
+				ExpectError: regexp.MustCompile("'OrderTest_Missing' is not a build configuration of project 'OrderTest'"),
+			},
+		},
+	})
+}
+
+const testAccTeamcityProjectOrderChildren = `
+resource "teamcity_project" "order_test" {
+	name = "order_test"
+}
+
+resource "teamcity_build_config" "build_a" {
+	name = "build_a"
+	project_id = "${teamcity_project.order_test.id}"
+}
+
+resource "teamcity_build_config" "build_b" {
+	name = "build_b"
+	project_id = "${teamcity_project.order_test.id}"
+}
+
+resource "teamcity_project" "sub_a" {
+	name = "sub_a"
+	parent_id = "${teamcity_project.order_test.id}"
+}
+
+resource "teamcity_project" "sub_b" {
+	name = "sub_b"
+	parent_id = "${teamcity_project.order_test.id}"
+}
+`
+
+var testAccTeamcityProjectOrder = testAccTeamcityProjectOrderChildren + `
+resource "teamcity_project_order" "order_test" {
+	project_id = "${teamcity_project.order_test.id}"
+	build_config_order = [teamcity_build_config.%s]
+	subproject_order = [teamcity_project.%s]
+}
+`
+
+var testAccTeamcityProjectOrderUnknownChild = testAccTeamcityProjectOrderChildren + `
+resource "teamcity_project_order" "order_test" {
+	project_id = "${teamcity_project.order_test.id}"
+	build_config_order = [teamcity_build_config.build_a.id, "OrderTest_Missing"]
+}
+`
@@ -1,13 +1,15 @@
 package teamcity_test
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"strings"
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcityProject_Basic(t *testing.T) {
@@ -30,6 +32,27 @@ func TestAccTeamcityProject_Basic(t *testing.T) {
 	})
 }
 
+func TestAccTeamcityProject_Disappears(t *testing.T) {
+	resName := "teamcity_project.testproj"
+	var p api.Project
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeamcityProjectConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTeamcityProjectExists(resName, &p),
+					testAccCheckTeamcityProjectDisappears(&p),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func TestAccTeamcityProject_Full(t *testing.T) {
 	resName := "teamcity_project.testproj"
 	var p api.Project
@@ -128,6 +151,110 @@ func TestAccTeamcityProject_Update(t *testing.T) {
 	})
 }
 
+func TestAccTeamcityProject_TypedParameters(t *testing.T) {
+	resName := "teamcity_project.testproj"
+	var p api.Project
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccTeamcityProjectTypedParameters, "config_value1", "s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTeamcityProjectExists(resName, &p),
+					resource.TestCheckResourceAttr(resName, "parameter.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":    "env.REGISTRY_PASSWORD",
+						"value":   testAccPasswordHash("s3cr3t"),
+						"type":    "password",
+						"display": "hidden",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":      "region",
+						"value":     "eu",
+						"type":      "select",
+						"label":     "Region",
+						"options.#": "2",
+					}),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value1"),
+					testAccCheckProjectParameter(&p, api.ParameterTypes.Configuration, "param1", "config_value1"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccTeamcityProjectTypedParameters, "config_value1", "n3w-s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "parameter.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":  "env.REGISTRY_PASSWORD",
+						"value": testAccPasswordHash("n3w-s3cr3t"),
+						"type":  "password",
+					}),
+				),
+			},
+			resource.TestStep{
+				// Updating plain parameters keeps the specification of typed ones
+				Config: fmt.Sprintf(testAccTeamcityProjectTypedParameters, "config_value2", "n3w-s3cr3t"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTeamcityProjectExists(resName, &p),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value2"),
+					testAccCheckProjectParameter(&p, api.ParameterTypes.Configuration, "param1", "config_value2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resName, "parameter.*", map[string]string{
+						"name":      "region",
+						"type":      "select",
+						"label":     "Region",
+						"options.#": "2",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTeamcityProject_PasswordParams(t *testing.T) {
+	resName := "teamcity_project.testproj"
+	var p api.Project
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeamcityProjectPasswordParams,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTeamcityProjectExists(resName, &p),
+					resource.TestCheckResourceAttr(resName, "password_params.%", "1"),
+					resource.TestCheckResourceAttr(resName, "password_params.env.REGISTRY_PASSWORD", testAccPasswordHash("s3cr3t")),
+					resource.TestCheckResourceAttr(resName, "config_params.param1", "config_value1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTeamcityProject_InheritedParams(t *testing.T) {
+	childRes := "teamcity_project.child"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTeamcityProjectDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeamcityProjectInheritedParams,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(childRes, "config_params.%", "1"),
+					resource.TestCheckResourceAttr(childRes, "config_params.child_param", "child"),
+					resource.TestCheckResourceAttr(childRes, "effective_params.child_param", "child"),
+					resource.TestCheckResourceAttr(childRes, "effective_params.parent_param", "parent"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckProjectParameter(dt *api.Project, paramType string, paramName string, paramValue string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		for _, i := range dt.Parameters.Items {
@@ -146,9 +273,14 @@ func testAccCheckProjectParameter(dt *api.Project, paramType string, paramName s
 	}
 }
 
+// testAccPasswordHash returns the hash a password parameter is stored as in the state
+func testAccPasswordHash(v string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(v)))
+}
+
 func testAccCheckTeamcityProjectExists(n string, project *api.Project) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return teamcityProjectExistsHelper(n, s, client, project)
 	}
 }
@@ -174,8 +306,16 @@ func teamcityProjectExistsHelper(n string, s *terraform.State, client *api.Clien
 	return nil
 }
 
+// testAccCheckTeamcityProjectDisappears deletes the project outside of Terraform
+func testAccCheckTeamcityProjectDisappears(p *api.Project) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
+		return client.Projects.Delete(p.ID)
+	}
+}
+
 func testAccCheckTeamcityProjectDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	return teamcityProjectDestroyHelper(s, client)
 }
 
@@ -249,3 +389,61 @@ resource "teamcity_project" "testproj" {
 	}
 }
 `
+
+const testAccTeamcityProjectTypedParameters = `
+resource "teamcity_project" "testproj" {
+	name = "test_project"
+
+	config_params = {
+		param1 = "%s"
+	}
+
+	parameter {
+		name = "env.REGISTRY_PASSWORD"
+		value = "%s"
+		type = "password"
+		display = "hidden"
+	}
+
+	parameter {
+		name = "region"
+		value = "eu"
+		type = "select"
+		label = "Region"
+		options = ["eu", "us"]
+	}
+}
+`
+
+const testAccTeamcityProjectPasswordParams = `
+resource "teamcity_project" "testproj" {
+	name = "test_project"
+
+	config_params = {
+		param1 = "config_value1"
+	}
+
+	password_params = {
+		"env.REGISTRY_PASSWORD" = "s3cr3t"
+	}
+}
+`
+
+const testAccTeamcityProjectInheritedParams = `
+resource "teamcity_project" "parent" {
+	name = "parent"
+
+	config_params = {
+		parent_param = "parent"
+	}
+}
+
+resource "teamcity_project" "child" {
+	name = "child"
+	parent_id = "${teamcity_project.parent.id}"
+
+	config_params = {
+		child_param = "child"
+	}
+}
+`
@@ -1,20 +1,23 @@
 package teamcity
 
 import (
-	"fmt"
+	"context"
+	"log"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceSnapshotDependency() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceSnapshotDependencyCreate,
-		Read:   resourceSnapshotDependencyRead,
-		Delete: resourceSnapshotDependencyDelete,
+		CreateContext: resourceSnapshotDependencyCreate,
+		ReadContext:   resourceSnapshotDependencyRead,
+		DeleteContext: resourceSnapshotDependencyDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceBuildConfigChildImport,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"build_config_id": {
@@ -31,60 +34,57 @@ func resourceSnapshotDependency() *schema.Resource {
 	}
 }
 
-func resourceSnapshotDependencyCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceSnapshotDependencyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var buildConfigID string
 
 	if v, ok := d.GetOk("build_config_id"); ok {
 		buildConfigID = v.(string)
 	}
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+
 	// validates the Build Configuration exists
-	if _, err := client.BuildTypes.GetByID(buildConfigID); err != nil {
-		return fmt.Errorf("invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
+	if _, err := meta.(*Meta).rest(ctx).getBuildType(buildConfigID); err != nil {
+		return attributeErrorf("build_config_id", "invalid build_config_id '%s' - Build configuration does not exist", buildConfigID)
 	}
 
-	depService := client.DependencyService(buildConfigID)
 	dep := api.NewSnapshotDependency(d.Get("source_build_config_id").(string))
 
-	out, err := depService.AddSnapshotDependency(dep)
+	out, err := meta.(*Meta).rest(ctx).addSnapshotDependency(buildConfigID, dep)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(out.ID)
 
-	return resourceSnapshotDependencyRead(d, meta)
+	return resourceSnapshotDependencyRead(ctx, d, meta)
 }
 
-func resourceSnapshotDependencyRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client).DependencyService(d.Get("build_config_id").(string))
-
-	dt, err := getSnapshotDependency(client, d.Id())
+func resourceSnapshotDependencyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	dt, err := meta.(*Meta).rest(ctx).getSnapshotDependency(d.Get("build_config_id").(string), d.Id())
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Snapshot dependency '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("build_config_id", dt.BuildTypeID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return d.Set("source_build_config_id", dt.SourceBuildType.ID)
-}
-
-func resourceSnapshotDependencyDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
-	dep := client.DependencyService(d.Get("build_config_id").(string))
-
-	return dep.DeleteSnapshot(d.Id())
+	return diag.FromErr(d.Set("source_build_config_id", dt.SourceBuildType.ID))
 }
 
-func getSnapshotDependency(c *api.DependencyService, id string) (*api.SnapshotDependency, error) {
-
-	dt, err := c.GetSnapshotByID(id)
-	if err != nil {
-		return nil, err
-	}
+func resourceSnapshotDependencyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
+	buildConfigID := d.Get("build_config_id").(string)
+	unlock := meta.(*Meta).lockBuildConfig(buildConfigID)
+	defer unlock()
+	dep := client.DependencyService(buildConfigID)
 
-	return dt, nil
+	return diag.FromErr(dep.DeleteSnapshot(d.Id()))
 }
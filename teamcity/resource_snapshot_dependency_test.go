@@ -6,8 +6,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccTeamcitySnapshotDependency_Basic(t *testing.T) {
@@ -29,6 +30,12 @@ func TestAccTeamcitySnapshotDependency_Basic(t *testing.T) {
 					testAccCheckSnapshotSourceBuildType(resName, &sd),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      resName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccBuildConfigChildImportStateIdFunc(resName),
+			},
 		},
 	})
 }
@@ -91,7 +98,7 @@ func testAccCheckSnapshotSourceBuildType(n string, sd *api.SnapshotDependency) r
 
 func testAccCheckTeamcitySnapshotDependencyDestroy(bt *string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return snapshotDependencyDestroyHelper(s, bt, client)
 	}
 }
@@ -119,7 +126,7 @@ func snapshotDependencyDestroyHelper(s *terraform.State, bt *string, client *api
 
 func testAccCheckTeamcitySnapshotDependencyExists(n string, bt *string, snap *api.SnapshotDependency) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return teamcitySnapshotDependencyExistsHelper(n, bt, s, client, snap)
 	}
 }
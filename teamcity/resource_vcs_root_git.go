@@ -2,25 +2,27 @@ package teamcity
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceVcsRootGit() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVcsRootGitCreate,
-		Read:   resourceVcsRootGitRead,
-		Update: resourceVcsRootGitUpdate,
-		Delete: resourceVcsRootGitDelete,
+		CreateContext: resourceVcsRootGitCreate,
+		ReadContext:   resourceVcsRootGitRead,
+		UpdateContext: resourceVcsRootGitUpdate,
+		DeleteContext: resourceVcsRootGitDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: updatableResourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -182,13 +184,13 @@ var expandCleanFilesPolicyMap = map[string]string{
 
 var flattenCleanFilesPolicyMap = reverseMap(expandCleanFilesPolicyMap)
 
-func resourceVcsRootGitCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceVcsRootGitCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	d.MarkNewResource()
-	return resourceVcsRootGitUpdate(d, meta)
+	return resourceVcsRootGitUpdate(ctx, d, meta)
 }
 
-func resourceVcsRootGitUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceVcsRootGitUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	projectID := d.Get("project_id").(string)
 	var gitVcs *api.GitVcsRoot
 	var name string
@@ -197,7 +199,7 @@ func resourceVcsRootGitUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcsOpts, err := expandGitVcsRootOptions(d)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if v, ok := d.GetOk("name"); ok {
@@ -218,23 +220,23 @@ func resourceVcsRootGitUpdate(d *schema.ResourceData, meta interface{}) error {
 	if d.IsNewResource() {
 		log.Printf("[INFO] detected new VCS Root resource, creating.")
 		if gitVcs, err = api.NewGitVcsRoot(projectID, name, vcsOpts); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		if modificationCheckInterval > 0 {
 			gitVcs.SetModificationCheckInterval(int32(modificationCheckInterval))
 		}
 		created, err := client.VcsRoots.Create(projectID, gitVcs)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		d.SetId(created.ID)
 
-		return resourceVcsRootGitRead(d, meta)
+		return resourceVcsRootGitRead(ctx, d, meta)
 	}
 	log.Printf("[INFO] Updating VCS Root resource.")
 	vcs, err := client.VcsRoots.GetByID(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	gitVcs = vcs.(*api.GitVcsRoot)
@@ -284,92 +286,97 @@ func resourceVcsRootGitUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	_, err = client.VcsRoots.Update(gitVcs)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceVcsRootGitRead(d, meta)
+	return resourceVcsRootGitRead(ctx, d, meta)
 }
 
-func resourceVcsRootGitRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceVcsRootGitRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	vcsID := d.Id()
 
 	vcs, err := client.VcsRoots.GetByID(vcsID)
 	if err != nil {
-		return err
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] VCS root '%s' not found - removing from state!", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	dt, ok := vcs.(*api.GitVcsRoot)
 	if !ok {
-		return fmt.Errorf("VCS with ID = %s has a type mismatch, not a Git VCS. Actual type: %s", vcsID, vcs.VcsName())
+		return diag.Errorf("VCS with ID = %s has a type mismatch, not a Git VCS. Actual type: %s", vcsID, vcs.VcsName())
 	}
 
 	if err := d.Set("project_id", dt.Project.ID); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("name", dt.Name()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if dt.ModificationCheckInterval() != nil {
 		v := *(dt.ModificationCheckInterval())
 		if err := d.Set("modification_check_interval", int(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if err := d.Set("fetch_url", dt.Options.FetchURL); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("push_url", dt.Options.PushURL); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("default_branch", dt.Options.DefaultBranch); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if len(dt.Options.BranchSpec) > 0 {
 		if err := d.Set("branches", flattenStringSlice(dt.Options.BranchSpec)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if auth, err := flattenGitVcsRootAuth(d, dt.Options); err != nil {
 		if err := d.Set("auth", auth); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if err := d.Set("submodule_checkout", dt.Options.SubModuleCheckout); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("enable_branch_spec_tags", dt.Options.EnableTagsInBranchSpec); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("username_style", flattenUsernameStyleMap[string(dt.Options.UsernameStyle)]); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if agent, err := flattenGitAgentSettings(d, dt.Options.AgentSettings); err != nil {
 		if err := d.Set("agent", agent); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	return nil
 }
 
-func resourceVcsRootGitDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+func resourceVcsRootGitDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*Meta).client(ctx)
 	log.Print(fmt.Sprintf("[DEBUG]: resourceVcsRootGitDelete - Destroying vcs root %v", d.Id()))
 	err := client.VcsRoots.Delete(d.Id())
 	log.Print(fmt.Sprintf("[INFO]: resourceVcsRootGitDelete - Destroyed vcs root %v", d.Id()))
-	return err
+	return diag.FromErr(err)
 }
 
 func expandGitVcsRootOptions(d *schema.ResourceData) (*api.GitVcsRootOptions, error) {
@@ -574,7 +581,7 @@ func gitVcsAuthHash(v interface{}) int {
 	var buf bytes.Buffer
 	m := v.(map[string]interface{})
 	buf.WriteString(fmt.Sprintf("%s-", m["type"].(string)))
-	hash := hashcode.String(buf.String())
+	hash := schema.HashString(buf.String())
 
 	log.Printf("[DEBUG] GitVcs Auth Hash: %d", hash)
 	return hash
@@ -7,8 +7,9 @@ import (
 	"testing"
 
 	api "github.com/cvbarros/go-teamcity/teamcity"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/cvbarros/terraform-provider-teamcity/teamcity"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 //Remove this test due to https://github.com/hashicorp/terraform/issues/23635
@@ -127,9 +128,11 @@ func TestAccVcsRootGit_UserpassAuth(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckVcsRootGitExists(resourceName, &vcs),
 					resource.TestCheckResourceAttr(resourceName, "auth.#", "1"),
-					resource.TestCheckResourceAttr(resourceName, "auth.2360613679.type", "userpass"),
-					resource.TestCheckResourceAttr(resourceName, "auth.2360613679.username", "admin"),
-					resource.TestCheckResourceAttrSet(resourceName, "auth.2360613679.password"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "auth.*", map[string]string{
+						"type":     "userpass",
+						"username": "admin",
+						"password": "admin",
+					}),
 				),
 			},
 		},
@@ -149,10 +152,12 @@ func TestAccVcsRootGit_SshUploadedKeyAuth(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckVcsRootGitExists(resourceName, &vcs),
 					resource.TestCheckResourceAttr(resourceName, "auth.#", "1"),
-					resource.TestCheckResourceAttr(resourceName, "auth.3867327421.type", "ssh"),
-					resource.TestCheckResourceAttr(resourceName, "auth.3867327421.username", "admin"),
-					resource.TestCheckResourceAttr(resourceName, "auth.3867327421.ssh_type", "uploadedKey"),
-					resource.TestCheckResourceAttr(resourceName, "auth.3867327421.key_spec", "myKey"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "auth.*", map[string]string{
+						"type":     "ssh",
+						"username": "admin",
+						"ssh_type": "uploadedKey",
+						"key_spec": "myKey",
+					}),
 				),
 			},
 		},
@@ -179,10 +184,12 @@ func TestAccVcsRootGit_AgentSettings(t *testing.T) {
 					testAccCheckVcsRootGitExists(resourceName, &vcs),
 					testAccCheckVcsRootGitAgentSettings(&vcs, expected),
 					resource.TestCheckResourceAttr(resourceName, "agent.#", "1"),
-					resource.TestCheckResourceAttr(resourceName, "agent.2618137949.git_path", "/usr/bin/git"),
-					resource.TestCheckResourceAttr(resourceName, "agent.2618137949.clean_policy", "branch_change"),
-					resource.TestCheckResourceAttr(resourceName, "agent.2618137949.clean_files_policy", "ignored_only"),
-					resource.TestCheckResourceAttr(resourceName, "agent.2618137949.use_mirrors", "true"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "agent.*", map[string]string{
+						"git_path":           "/usr/bin/git",
+						"clean_policy":       "branch_change",
+						"clean_files_policy": "ignored_only",
+						"use_mirrors":        "true",
+					}),
 				),
 			},
 		},
@@ -207,7 +214,7 @@ func TestAccVcsRootGit_Delete(t *testing.T) {
 
 func testAccCheckVcsRootGitExists(name string, out *api.GitVcsRoot) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		return vcsRootGitExistsHelper(s, client, out)
 	}
 }
@@ -231,7 +238,7 @@ func vcsRootGitExistsHelper(s *terraform.State, client *api.Client, out *api.Git
 }
 
 func testAccCheckVcsRootGitDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*api.Client)
+	client := testAccProvider.Meta().(*teamcity.Meta).Client
 	return vcsRootGitDestroyHelper(s, client)
 }
 
@@ -257,7 +264,7 @@ func vcsRootGitDestroyHelper(s *terraform.State, client *api.Client) error {
 
 func testAccCheckVcsRootGitAgentSettings(vcs *api.GitVcsRoot, expected map[string]string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*api.Client)
+		client := testAccProvider.Meta().(*teamcity.Meta).Client
 		dt, err := client.VcsRoots.GetByID((*vcs).ID)
 		if err != nil {
 			return err
@@ -310,6 +317,10 @@ resource "teamcity_vcs_root_git" "git_test" {
 	submodule_checkout = "checkout"
 	enable_branch_spec_tags = true
 	modification_check_interval = 60
+
+	timeouts {
+		create = "10m"
+	}
 }
 `
 
@@ -0,0 +1,130 @@
+package teamcity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// restClient calls the TeamCity REST API directly, for the endpoints and payloads the api client doesn't support.
+// It shares the api client settings: address, credentials and transport.
+type restClient struct {
+	httpClient *http.Client
+	config     *Config
+}
+
+func (c *Config) newRestClient(httpClient *http.Client) *restClient {
+	return &restClient{
+		httpClient: httpClient,
+		config:     c,
+	}
+}
+
+func (r *restClient) get(path string, out interface{}, resourceDescription string) error {
+	return r.do(http.MethodGet, path, nil, out, resourceDescription)
+}
+
+func (r *restClient) post(path string, data interface{}, out interface{}, resourceDescription string) error {
+	return r.do(http.MethodPost, path, data, out, resourceDescription)
+}
+
+func (r *restClient) put(path string, data interface{}, out interface{}, resourceDescription string) error {
+	return r.do(http.MethodPut, path, data, out, resourceDescription)
+}
+
+func (r *restClient) delete(path string, resourceDescription string) error {
+	return r.do(http.MethodDelete, path, nil, nil, resourceDescription)
+}
+
+// getText returns the plain text value of a single field
+func (r *restClient) getText(path string, resourceDescription string) (string, error) {
+	dt, err := r.send(http.MethodGet, path, "", nil, "text/plain", resourceDescription)
+	if err != nil {
+		return "", err
+	}
+	return string(dt), nil
+}
+
+// putText sends value as plain text, which is how TeamCity sets single fields like build configuration settings
+func (r *restClient) putText(path string, value string, resourceDescription string) error {
+	_, err := r.send(http.MethodPut, path, "text/plain", strings.NewReader(value), "text/plain", resourceDescription)
+	return err
+}
+
+// do sends data as JSON and decodes the JSON response into out, if any
+func (r *restClient) do(method string, path string, data interface{}, out interface{}, resourceDescription string) error {
+	var body io.Reader
+	var contentType string
+	if data != nil {
+		dt, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dt)
+		contentType = "application/json"
+	}
+
+	dt, err := r.send(method, path, contentType, body, "application/json", resourceDescription)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(dt) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(dt, out); err != nil {
+		return fmt.Errorf("error reading %s: %s", resourceDescription, err)
+	}
+	return nil
+}
+
+// requestError is returned by the rest client when TeamCity answers with an error status
+type requestError struct {
+	StatusCode          int
+	Method              string
+	ResourceDescription string
+	Body                string
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("Error '%d' when performing '%s' operation - %s: %s", e.StatusCode, e.Method, e.ResourceDescription, e.Body)
+}
+
+// send performs the request and returns the response body, or a requestError if TeamCity answers with an error status
+func (r *restClient) send(method string, path string, contentType string, body io.Reader, accept string, resourceDescription string) ([]byte, error) {
+	req, err := http.NewRequest(method, r.config.restURL(path), body)
+	if err != nil {
+		return nil, err
+	}
+	r.config.authorize(req)
+	req.Header.Set("Accept", accept)
+	// TeamCity rejects changes from another origin as CSRF attempts
+	req.Header.Set("Origin", r.config.Address)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	dt, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &requestError{
+			StatusCode:          resp.StatusCode,
+			Method:              method,
+			ResourceDescription: resourceDescription,
+			Body:                string(dt),
+		}
+	}
+	return dt, nil
+}
@@ -0,0 +1,39 @@
+package teamcity
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Default timeouts of resource operations, which can be changed with a `timeouts` block.
+// They bound the requests sent to the server for the operation, so an unresponsive server fails it instead of leaving Terraform hanging.
+const (
+	defaultCreateTimeout = 5 * time.Minute
+	defaultReadTimeout   = 2 * time.Minute
+	defaultUpdateTimeout = 5 * time.Minute
+	defaultDeleteTimeout = 5 * time.Minute
+)
+
+// resourceTimeouts returns the timeouts of resources replaced on every change, which have no update operation
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultCreateTimeout),
+		Read:   schema.DefaultTimeout(defaultReadTimeout),
+		Delete: schema.DefaultTimeout(defaultDeleteTimeout),
+	}
+}
+
+// updatableResourceTimeouts returns the timeouts of resources that can be updated in place
+func updatableResourceTimeouts() *schema.ResourceTimeout {
+	t := resourceTimeouts()
+	t.Update = schema.DefaultTimeout(defaultUpdateTimeout)
+	return t
+}
+
+// dataSourceTimeouts returns the timeouts of data sources, which only read
+func dataSourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Read: schema.DefaultTimeout(defaultReadTimeout),
+	}
+}
@@ -0,0 +1,252 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// retryTransport retries requests that failed because the TeamCity server was temporarily unavailable,
+// like during a server restart or when a proxy in front of it returns 502/503.
+// Waits between attempts grow exponentially from waitMin up to waitMax, with jitter.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	waitMin    time.Duration
+	waitMax    time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper, maxRetries int, waitMin time.Duration, waitMax time.Duration) *retryTransport {
+	if waitMax < waitMin {
+		waitMax = waitMin
+	}
+	return &retryTransport{
+		next:       next,
+		maxRetries: maxRetries,
+		waitMin:    waitMin,
+		waitMax:    waitMax,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			var err error
+			if r, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if attempt >= t.maxRetries || !shouldRetryRequest(r, resp, err) {
+			return resp, err
+		}
+		// Without a way to rewind the body, the request can't be sent again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if err != nil {
+			log.Printf("[DEBUG] %s %s failed: %s. Retrying in %s (%d/%d)", req.Method, req.URL, err, wait, attempt+1, t.maxRetries)
+		} else {
+			log.Printf("[DEBUG] %s %s returned '%s'. Retrying in %s (%d/%d)", req.Method, req.URL, resp.Status, wait, attempt+1, t.maxRetries)
+			drainBody(resp)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt. A 'Retry-After' header sent by the server takes precedence.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			if wait := time.Duration(s) * time.Second; wait < t.waitMax {
+				return wait
+			}
+			return t.waitMax
+		}
+	}
+
+	wait := t.waitMin
+	for i := 0; i < attempt && wait < t.waitMax; i++ {
+		wait *= 2
+	}
+	if wait > t.waitMax {
+		wait = t.waitMax
+	}
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+	return wait
+}
+
+func shouldRetryRequest(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// A request that failed while dialing never reached the server, so even non-idempotent ones are safe to send again
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		return isIdempotent(req.Method) && isTransientNetworkError(err)
+	}
+
+	// A throttled request wasn't processed by the server, so it can always be sent again
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return isIdempotent(req.Method) && resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+func isTransientNetworkError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// drainBody reads what is left of the response so the underlying connection can be reused
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+}
+
+// limitTransport keeps the provider within the request rate and concurrency that the TeamCity server can handle.
+// A request holds its concurrency slot until its response body is closed.
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// newLimitTransport returns a transport allowing up to requestsPerSecond requests per second and
+// maxConcurrent requests in flight. Zero disables the corresponding limit.
+func newLimitTransport(next http.RoundTripper, requestsPerSecond float64, maxConcurrent int) *limitTransport {
+	t := &limitTransport{next: next}
+	if requestsPerSecond > 0 {
+		burst := int(requestsPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if t.slots != nil {
+			<-t.slots
+		}
+	}
+
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// contextTransport sends requests created without a context, like the ones from the api client, with ctx instead.
+// This way they are cancelled when ctx is, like when Terraform is interrupted.
+type contextTransport struct {
+	next http.RoundTripper
+	ctx  context.Context
+}
+
+func newContextTransport(next http.RoundTripper, ctx context.Context) *contextTransport {
+	return &contextTransport{next: next, ctx: ctx}
+}
+
+// errOperationTimeout is returned for requests that didn't complete before the deadline of the operation sending them
+var errOperationTimeout = errors.New("timeout waiting for the TeamCity server: the operation didn't complete within its timeout, which can be increased with a `timeouts` block on the resource")
+
+// RoundTrip implements http.RoundTripper
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context() == context.Background() {
+		req = req.WithContext(t.ctx)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil && req.Context().Err() == context.DeadlineExceeded {
+		return nil, errOperationTimeout
+	}
+	return resp, err
+}